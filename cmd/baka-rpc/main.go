@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/bob620/baka-rpc-go/openrpc"
+	"github.com/bob620/baka-rpc-go/openrpc/gen"
+)
+
+const usage = `usage: baka-rpc <command> [flags]
+
+commands:
+  gen    generate a typed Go client and server from an OpenRPC document
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "gen":
+		if err := generate(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, "baka-rpc gen:", err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func generate(args []string) error {
+	flags := flag.NewFlagSet("gen", flag.ExitOnError)
+	in := flags.String("in", "openrpc.json", "OpenRPC document to read")
+	out := flags.String("out", "", "Go file to write, stdout if empty")
+	packageName := flags.String("package", "main", "package of the generated code")
+	_ = flags.Parse(args)
+
+	document, err := openrpc.ReadFile(*in)
+	if err != nil {
+		return err
+	}
+
+	source, err := gen.Generate(document, *packageName)
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err = os.Stdout.Write(source)
+		return err
+	}
+	return os.WriteFile(*out, source, 0644)
+}
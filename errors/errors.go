@@ -1,8 +1,28 @@
 package errors
 
+import (
+	"encoding/json"
+)
+
 type RPCError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// Error lets handlers return an RPCError as an error to answer with its code instead of a generic error
+func (err *RPCError) Error() string {
+	if err == nil {
+		return "<nil>"
+	}
+	return err.Message
+}
+
+// WithData returns a copy of the error carrying data, data that cannot be marshalled is left out
+func (err *RPCError) WithData(data interface{}) *RPCError {
+	withData := *err
+	withData.Data, _ = json.Marshal(data)
+	return &withData
 }
 
 func NewParseError() *RPCError {
@@ -46,3 +66,31 @@ func NewGenericError(err string) *RPCError {
 		Message: err,
 	}
 }
+
+func NewServerBusy() *RPCError {
+	return &RPCError{
+		Code:    -32001,
+		Message: "Server busy",
+	}
+}
+
+func NewTimeout() *RPCError {
+	return &RPCError{
+		Code:    -32002,
+		Message: "Timeout",
+	}
+}
+
+func NewRequestCancelled() *RPCError {
+	return &RPCError{
+		Code:    -32800,
+		Message: "Request cancelled",
+	}
+}
+
+func NewUnauthorized() *RPCError {
+	return &RPCError{
+		Code:    -32003,
+		Message: "Unauthorized",
+	}
+}
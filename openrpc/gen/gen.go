@@ -0,0 +1,402 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/bob620/baka-rpc-go/openrpc"
+)
+
+type methodView struct {
+	Name        string
+	GoName      string
+	Description string
+	// Comment is the description folded onto a single line
+	Comment    string
+	Kind       string
+	Params     []paramView
+	ResultType string
+	// ByPosition methods are called with every param in order, nil optional params are sent as null
+	ByPosition bool
+	// ResultName and ResultSchema are emitted as the method's rpc.MethodResult, ResultSchema is JSON
+	ResultName   string
+	ResultSchema string
+}
+
+type paramView struct {
+	Name   string
+	GoName string
+	GoType string
+	// Pointer params are optional without a default, nil leaves them out of calls by name
+	Pointer      bool
+	Registration string
+}
+
+// reserved are the identifiers used by the generated code itself, including its imports and helpers
+var reserved = map[string]bool{
+	"ctx": true, "client": true, "params": true, "result": true, "err": true, "res": true, "resErr": true,
+	"sink": true, "stream": true, "server": true, "bakaRpc": true, "sub": true, "data": true,
+	"context": true, "json": true, "errors": true, "parameters": true, "rpc": true, "UUID": true,
+	"appendParam": true, "decodeParam": true, "decodeSchema": true,
+}
+
+// Generate emits Go source for package packageName with a typed Client, a Server interface and RegisterServer glue
+func Generate(document *openrpc.Document, packageName string) ([]byte, error) {
+	var methods []methodView
+	names := map[string]string{}
+	usesErrors := false
+	usesResults := false
+	for _, method := range document.Methods {
+		if strings.HasPrefix(method.Name, "rpc.") {
+			continue
+		}
+
+		view, err := newMethodView(method)
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := names[view.GoName]; ok {
+			return nil, fmt.Errorf("methods %q and %q both generate %s", other, method.Name, view.GoName)
+		}
+		names[view.GoName] = method.Name
+		if len(view.Params) > 0 {
+			usesErrors = true
+		}
+		if view.ResultSchema != "" {
+			usesResults = true
+		}
+		methods = append(methods, view)
+	}
+
+	buffer := bytes.Buffer{}
+	err := fileTemplate.Execute(&buffer, map[string]interface{}{
+		"Package":     packageName,
+		"Info":        document.Info,
+		"Methods":     methods,
+		"UsesErrors":  usesErrors,
+		"UsesResults": usesResults,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := format.Source(buffer.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("generated invalid code: %w", err)
+	}
+
+	return source, nil
+}
+
+func newMethodView(method openrpc.Method) (methodView, error) {
+	view := methodView{
+		Name:        method.Name,
+		GoName:      exportedName(method.Name),
+		Description: method.Description,
+		Comment:     strings.Join(strings.Fields(method.Description), " "),
+		Kind:        method.Kind,
+		ResultType:  "json.RawMessage",
+	}
+
+	if view.GoName == "" {
+		return view, fmt.Errorf("method %q has no usable Go name", method.Name)
+	}
+
+	switch method.ParamStructure {
+	case "", "either", "by-name":
+	case "by-position":
+		view.ByPosition = true
+	default:
+		return view, fmt.Errorf("method %q has unknown paramStructure %q", method.Name, method.ParamStructure)
+	}
+
+	if method.Result != nil {
+		view.ResultType = goType(method.Result.Schema)
+
+		schema, err := json.Marshal(method.Result.Schema)
+		if err != nil {
+			return view, fmt.Errorf("method %q: %w", method.Name, err)
+		}
+		view.ResultName = method.Result.Name
+		view.ResultSchema = string(schema)
+	}
+
+	names := map[string]string{}
+	for _, param := range method.Params {
+		paramView, err := newParamView(param)
+		if err != nil {
+			return view, fmt.Errorf("method %q: %w", method.Name, err)
+		}
+		if other, ok := names[paramView.GoName]; ok {
+			return view, fmt.Errorf("method %q: params %q and %q both generate %s", method.Name, other, param.Name, paramView.GoName)
+		}
+		names[paramView.GoName] = param.Name
+		view.Params = append(view.Params, paramView)
+	}
+
+	return view, nil
+}
+
+func newParamView(param openrpc.ContentDescriptor) (paramView, error) {
+	goName := unexportedName(param.Name)
+	if goName == "" {
+		return paramView{}, fmt.Errorf("param %q has no usable Go name", param.Name)
+	}
+	if reserved[goName] || token.Lookup(goName).IsKeyword() {
+		goName += "Param"
+	}
+
+	defaultValue, hasDefault := param.Schema["default"]
+	view := paramView{
+		Name:    param.Name,
+		GoName:  goName,
+		GoType:  goType(param.Schema),
+		Pointer: !param.Required && !hasDefault,
+	}
+
+	registration, err := registration(param, view.GoType, defaultValue, hasDefault)
+	if err != nil {
+		return view, err
+	}
+	view.Registration = registration
+
+	if view.Pointer {
+		view.GoType = "*" + view.GoType
+	}
+
+	return view, nil
+}
+
+// registration returns the parameters.Param expression declaring the param, optional params without a default are
+// GenericParams so their absence can be told apart from the zero value
+func registration(param openrpc.ContentDescriptor, goType string, defaultValue interface{}, hasDefault bool) (string, error) {
+	fields := fmt.Sprintf("Name: %q, Required: %t", param.Name, param.Required)
+	if param.Description != "" {
+		fields += fmt.Sprintf(", Description: %q", param.Description)
+	}
+
+	typed := map[string]string{"string": "StringParam", "int": "IntParam", "bool": "BoolParam"}[goType]
+	if typed == "" || (!param.Required && !hasDefault) {
+		if hasDefault {
+			data, err := json.Marshal(defaultValue)
+			if err != nil {
+				return "", err
+			}
+			fields += fmt.Sprintf(", Default: json.RawMessage(%s)", strconv.Quote(string(data)))
+		}
+		return fmt.Sprintf("&parameters.GenericParam{%s}", fields), nil
+	}
+
+	if hasDefault {
+		switch value := defaultValue.(type) {
+		case string:
+			fields += fmt.Sprintf(", Default: %s", strconv.Quote(value))
+		case float64:
+			fields += fmt.Sprintf(", Default: %d", int(value))
+		case bool:
+			fields += fmt.Sprintf(", Default: %t", value)
+		default:
+			return "", fmt.Errorf("param %q has a default that is not a %s", param.Name, goType)
+		}
+	}
+
+	return fmt.Sprintf("&parameters.%s{%s}", typed, fields), nil
+}
+
+func goType(schema map[string]interface{}) string {
+	switch schema["type"] {
+	case "string":
+		return "string"
+	case "integer":
+		return "int"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "object":
+		return "map[string]interface{}"
+	case "array":
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			return "[]" + goType(items)
+		}
+		return "[]json.RawMessage"
+	}
+
+	return "json.RawMessage"
+}
+
+func words(name string) []string {
+	return strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func exportedName(name string) string {
+	result := ""
+	for _, word := range words(name) {
+		runes := []rune(word)
+		result += string(unicode.ToUpper(runes[0])) + string(runes[1:])
+	}
+
+	if result != "" && unicode.IsDigit([]rune(result)[0]) {
+		result = "M" + result
+	}
+
+	return result
+}
+
+func unexportedName(name string) string {
+	result := []rune(exportedName(name))
+	if len(result) == 0 {
+		return ""
+	}
+
+	result[0] = unicode.ToLower(result[0])
+	return string(result)
+}
+
+var fileTemplate = template.Must(template.New("file").Parse(`// Code generated by baka-rpc gen. DO NOT EDIT.
+{{- if .Info.Title}}
+// Source: {{.Info.Title}} {{.Info.Version}}
+{{- end}}
+
+package {{.Package}}
+
+import (
+	"context"
+	"encoding/json"
+
+	UUID "github.com/nu7hatch/gouuid"
+
+	{{if .UsesErrors}}"github.com/bob620/baka-rpc-go/errors"{{end}}
+	"github.com/bob620/baka-rpc-go/parameters"
+	"github.com/bob620/baka-rpc-go/rpc"
+)
+
+// Client calls the methods on Channel, a nil Channel uses any attached channel
+type Client struct {
+	RPC     *rpc.BakaRpc
+	Channel *UUID.UUID
+}
+
+func NewClient(bakaRpc *rpc.BakaRpc, channel *UUID.UUID) *Client {
+	return &Client{RPC: bakaRpc, Channel: channel}
+}
+
+func appendParam(params []parameters.Param, name string, value interface{}) ([]parameters.Param, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(params, &parameters.GenericParam{Name: name, Default: data}), nil
+}
+{{range .Methods}}
+{{if .Comment}}// {{.GoName}} {{.Comment}}
+{{end -}}
+func (client *Client) {{.GoName}}(ctx context.Context{{range .Params}}, {{.GoName}} {{.GoType}}{{end}}) (
+	{{- if eq .Kind "subscription"}}sub *rpc.Subscription{{else if eq .Kind "stream"}}stream *rpc.StreamReader{{else}}result {{.ResultType}}{{end}}, err error) {
+	params := []parameters.Param{}
+	{{- $byPosition := .ByPosition}}
+	{{- range .Params}}
+	{{if and .Pointer (not $byPosition)}}if {{.GoName}} != nil {
+		if params, err = appendParam(params, {{printf "%q" .Name}}, {{.GoName}}); err != nil {
+			return
+		}
+	}{{else}}if params, err = appendParam(params, {{printf "%q" .Name}}, {{.GoName}}); err != nil {
+		return
+	}{{end}}
+	{{- end}}
+{{if eq .Kind "subscription"}}
+	sub, resErr := client.RPC.Subscribe(ctx, client.Channel, {{printf "%q" .Name}}, {{if .ByPosition}}parameters.NewParametersByPosition(params){{else}}parameters.NewParametersByName(params){{end}})
+	if resErr != nil {
+		return nil, resErr
+	}
+	return sub, nil
+{{- else if eq .Kind "stream"}}
+	stream, resErr := client.RPC.CallStream(ctx, client.Channel, {{printf "%q" .Name}}, {{if .ByPosition}}parameters.NewParametersByPosition(params){{else}}parameters.NewParametersByName(params){{end}})
+	if resErr != nil {
+		return nil, resErr
+	}
+	return stream, nil
+{{- else}}
+	res, resErr := client.RPC.CallMethodContext(ctx, client.Channel, {{printf "%q" .Name}}, {{if .ByPosition}}parameters.NewParametersByPosition(params){{else}}parameters.NewParametersByName(params){{end}})
+	if resErr != nil {
+		err = resErr
+		return
+	}
+	if res != nil {
+		err = json.Unmarshal(*res, &result)
+	}
+	return
+{{- end}}
+}
+{{end}}
+// Server implements the methods registered by RegisterServer
+type Server interface {
+{{- range .Methods}}
+	{{if .Comment}}// {{.GoName}} {{.Comment}}
+	{{end -}}
+	{{.GoName}}(ctx context.Context{{range .Params}}, {{.GoName}} {{.GoType}}{{end}}
+	{{- if eq .Kind "subscription"}}, sink *rpc.Sink) error
+	{{- else if eq .Kind "stream"}}, stream *rpc.StreamWriter) error
+	{{- else}}) ({{.ResultType}}, error){{end}}
+{{- end}}
+}
+
+// RegisterServer registers every method of server, decoding the params before calling it
+func RegisterServer(bakaRpc *rpc.BakaRpc, server Server) {
+{{- range .Methods}}
+	{{if eq .Kind "subscription"}}bakaRpc.RegisterSubscription({{else if eq .Kind "stream"}}bakaRpc.RegisterStreamMethod({{else}}bakaRpc.RegisterContextMethod({{end}}{{printf "%q" .Name}}, []parameters.Param{
+		{{- range .Params}}
+		{{.Registration}},
+		{{- end}}
+	}, func(ctx context.Context, params map[string]parameters.Param{{if eq .Kind "subscription"}}, sink *rpc.Sink) error {{else if eq .Kind "stream"}}, stream *rpc.StreamWriter) error {{else}}) (json.RawMessage, error) {{end}}{
+		{{- $kind := .Kind}}
+		{{- range .Params}}
+		var {{.GoName}} {{.GoType}}
+		if err := decodeParam(params, {{printf "%q" .Name}}, &{{.GoName}}); err != nil {
+			return {{if or (eq $kind "subscription") (eq $kind "stream")}}err{{else}}nil, err{{end}}
+		}
+		{{- end}}
+{{if .Params}}
+{{end -}}
+{{if eq .Kind "subscription"}}		return server.{{.GoName}}(ctx{{range .Params}}, {{.GoName}}{{end}}, sink)
+{{- else if eq .Kind "stream"}}		return server.{{.GoName}}(ctx{{range .Params}}, {{.GoName}}{{end}}, stream)
+{{- else}}		result, err := server.{{.GoName}}(ctx{{range .Params}}, {{.GoName}}{{end}})
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(result)
+{{- end}}
+	}{{if .Description}}, rpc.MethodDescription({{printf "%q" .Description}}){{end}}
+	{{- if .ResultSchema}}, rpc.MethodResult({{printf "%q" .ResultName}}, decodeSchema({{printf "%q" .ResultSchema}})){{end}})
+{{- end}}
+}
+{{if .UsesErrors}}
+func decodeParam(params map[string]parameters.Param, name string, value interface{}) error {
+	data := params[name].GetData()
+	if data == nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(data, value); err != nil {
+		return errors.NewInvalidParams()
+	}
+	return nil
+}
+{{end}}
+{{- if .UsesResults}}
+func decodeSchema(data string) map[string]interface{} {
+	schema := map[string]interface{}{}
+	_ = json.Unmarshal([]byte(data), &schema)
+	return schema
+}
+{{end}}`))
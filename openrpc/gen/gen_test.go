@@ -0,0 +1,102 @@
+package gen
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/bob620/baka-rpc-go/openrpc"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+func TestGenerateGolden(t *testing.T) {
+	source := generate(t, "testdata/items.json")
+
+	if *update {
+		if err := os.WriteFile("testdata/items.go.golden", source, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	golden, err := os.ReadFile("testdata/items.go.golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(source, golden) {
+		t.Errorf("generated code differs from testdata/items.go.golden, rerun with -update if the change is intended\n%s", source)
+	}
+}
+
+func TestGenerateCompiles(t *testing.T) {
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+
+	// Built inside the module so the generated imports resolve, the underscore keeps it out of ./...
+	dir, err := os.MkdirTemp(".", "_build")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = os.WriteFile(filepath.Join(dir, "items.go"), generate(t, "testdata/items.json"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := exec.Command(goTool, "vet", "./"+filepath.Base(dir)).CombinedOutput()
+	if err != nil {
+		t.Fatalf("generated code does not build: %v\n%s", err, output)
+	}
+}
+
+func TestGenerateRejectsUnknownParamStructure(t *testing.T) {
+	document := &openrpc.Document{Methods: []openrpc.Method{{Name: "add", ParamStructure: "by-magic"}}}
+
+	if _, err := Generate(document, "items"); err == nil {
+		t.Fatal("expected an error for an unknown paramStructure")
+	}
+}
+
+func TestGenerateRejectsCollidingNames(t *testing.T) {
+	param := func(name string) openrpc.ContentDescriptor {
+		return openrpc.ContentDescriptor{Name: name, Schema: map[string]interface{}{"type": "string"}}
+	}
+
+	tests := []struct {
+		name    string
+		methods []openrpc.Method
+	}{
+		{"methods", []openrpc.Method{{Name: "user.get"}, {Name: "userGet"}}},
+		{"params", []openrpc.Method{{Name: "find", Params: []openrpc.ContentDescriptor{param("item_id"), param("itemId")}}}},
+		{"reserved params", []openrpc.Method{{Name: "find", Params: []openrpc.ContentDescriptor{param("type"), param("typeParam")}}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Generate(&openrpc.Document{Methods: test.methods}, "items"); err == nil {
+				t.Fatal("expected an error for colliding Go names")
+			}
+		})
+	}
+}
+
+func generate(t *testing.T, path string) []byte {
+	t.Helper()
+
+	document, err := openrpc.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := Generate(document, "items")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return source
+}
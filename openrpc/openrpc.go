@@ -0,0 +1,93 @@
+package openrpc
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/bob620/baka-rpc-go/parameters"
+)
+
+const Version = "1.2.6"
+
+// Kinds of Baka-RPC methods, plain JSON-RPC methods leave the kind empty
+const (
+	KindSubscription = "subscription"
+	KindStream       = "stream"
+)
+
+type Document struct {
+	OpenRPC string   `json:"openrpc"`
+	Info    Info     `json:"info"`
+	Methods []Method `json:"methods"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Method struct {
+	Name           string              `json:"name"`
+	Description    string              `json:"description,omitempty"`
+	Params         []ContentDescriptor `json:"params"`
+	Result         *ContentDescriptor  `json:"result,omitempty"`
+	ParamStructure string              `json:"paramStructure,omitempty"`
+	Deprecated     bool                `json:"deprecated,omitempty"`
+	// Kind is a Baka-RPC extension telling generated clients to Subscribe or CallStream instead of CallMethod
+	Kind string `json:"x-baka-kind,omitempty"`
+}
+
+type ContentDescriptor struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Required    bool                   `json:"required,omitempty"`
+	Schema      map[string]interface{} `json:"schema"`
+}
+
+func NewDocument(info Info) *Document {
+	return &Document{
+		OpenRPC: Version,
+		Info:    info,
+		Methods: []Method{},
+	}
+}
+
+// DescribeParam builds the content descriptor of a param, params without a Describer get an empty schema
+func DescribeParam(param parameters.Param) ContentDescriptor {
+	descriptor := ContentDescriptor{
+		Name:     param.GetName(),
+		Required: param.IsRequired(),
+		Schema:   map[string]interface{}{},
+	}
+
+	if describer, ok := param.(parameters.Describer); ok {
+		descriptor.Description = describer.GetDescription()
+		descriptor.Schema = describer.Schema()
+	}
+
+	return descriptor
+}
+
+func ReadFile(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	document := &Document{}
+	if err = json.Unmarshal(data, document); err != nil {
+		return nil, err
+	}
+
+	return document, nil
+}
+
+func (document *Document) WriteFile(path string) error {
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
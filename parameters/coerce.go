@@ -0,0 +1,153 @@
+package parameters
+
+import (
+	"encoding/json"
+)
+
+// Coercible is implemented by params that can convert compatible representations of their value for lenient callers
+type Coercible interface {
+	// CoerceData returns data converted to the param's type, ok is false when data is left as it is
+	CoerceData(data json.RawMessage) (coerced json.RawMessage, ok bool)
+	// CoercionEnabled reports whether the param was declared with Coerce
+	CoercionEnabled() bool
+}
+
+// CoerceData converts numeric strings such as "42" to integers
+func (param *IntParam) CoerceData(data json.RawMessage) (json.RawMessage, bool) {
+	return coerceInteger(data)
+}
+
+func (param *IntParam) CoercionEnabled() bool {
+	return param.Coerce
+}
+
+// CoerceData converts "true", "false", "1", "0", 1 and 0 to booleans
+func (param *BoolParam) CoerceData(data json.RawMessage) (json.RawMessage, bool) {
+	return coerceBoolean(data)
+}
+
+func (param *BoolParam) CoercionEnabled() bool {
+	return param.Coerce
+}
+
+// CoerceData converts numeric strings such as "4.2" to numbers
+func (param *float64Param) CoerceData(data json.RawMessage) (json.RawMessage, bool) {
+	return coerceNumber(data)
+}
+
+func (param *float64Param) CoercionEnabled() bool {
+	return param.Coerce
+}
+
+// CoerceData converts numeric strings such as "19.99" to numbers, keeping every digit
+func (param *NumberParam) CoerceData(data json.RawMessage) (json.RawMessage, bool) {
+	if param.Integer {
+		return coerceInteger(data)
+	}
+	return coerceNumber(data)
+}
+
+func (param *NumberParam) CoercionEnabled() bool {
+	return param.Coerce
+}
+
+// CoerceData applies the first coercion allowed by the schema's top level type: a single value into a one element
+// array, a numeric string into a number or a boolean representation into a boolean
+func (param *SchemaParam) CoerceData(data json.RawMessage) (json.RawMessage, bool) {
+	if param.schema == nil || len(param.schema.types) == 0 {
+		return data, false
+	}
+
+	value, err := decodeValue(data)
+	if err != nil {
+		return data, false
+	}
+	for _, typeName := range param.schema.types {
+		if isType(value, typeName) {
+			return data, false
+		}
+	}
+
+	for _, typeName := range param.schema.types {
+		var coerced json.RawMessage
+		ok := false
+		switch typeName {
+		case "array":
+			coerced, ok = coerceArray(data)
+		case "integer":
+			coerced, ok = coerceInteger(data)
+		case "number":
+			coerced, ok = coerceNumber(data)
+		case "boolean":
+			coerced, ok = coerceBoolean(data)
+		}
+
+		if ok {
+			return coerced, true
+		}
+	}
+
+	return data, false
+}
+
+func (param *SchemaParam) CoercionEnabled() bool {
+	return param.Coerce
+}
+
+// numericString returns the number held by a JSON string, such as 42 from "42"
+func numericString(data json.RawMessage) (json.Number, bool) {
+	var text string
+	if json.Unmarshal(data, &text) != nil {
+		return "", false
+	}
+
+	value, err := decodeValue(json.RawMessage(text))
+	number, ok := value.(json.Number)
+	return number, err == nil && ok
+}
+
+func coerceNumber(data json.RawMessage) (json.RawMessage, bool) {
+	number, ok := numericString(data)
+	if !ok {
+		return data, false
+	}
+
+	return json.RawMessage(number), true
+}
+
+func coerceInteger(data json.RawMessage) (json.RawMessage, bool) {
+	number, ok := numericString(data)
+	if !ok || !isType(number, "integer") {
+		return data, false
+	}
+
+	return json.RawMessage(number), true
+}
+
+func coerceBoolean(data json.RawMessage) (json.RawMessage, bool) {
+	var text string
+	switch value, _ := decodeValue(data); typed := value.(type) {
+	case string:
+		text = typed
+	case json.Number:
+		text = typed.String()
+	}
+
+	switch text {
+	case "true", "1":
+		return json.RawMessage("true"), true
+	case "false", "0":
+		return json.RawMessage("false"), true
+	}
+
+	return data, false
+}
+
+func coerceArray(data json.RawMessage) (json.RawMessage, bool) {
+	value, err := decodeValue(data)
+	if err != nil || value == nil || isType(value, "array") {
+		return data, false
+	}
+
+	return json.RawMessage("[" + string(data) + "]"), true
+}
@@ -0,0 +1,66 @@
+package parameters
+
+// Describer is implemented by params that can describe themselves for introspection, such as OpenRPC documents
+type Describer interface {
+	GetDescription() string
+	// Schema returns the JSON Schema of the param's value
+	Schema() map[string]interface{}
+}
+
+// typeSchema leaves out the default of required params, it is never used
+func typeSchema(typeName string, required bool, nullable bool, defaultValue interface{}) map[string]interface{} {
+	schema := map[string]interface{}{}
+	if typeName != "" && nullable {
+		schema["type"] = []string{typeName, "null"}
+	} else if typeName != "" {
+		schema["type"] = typeName
+	}
+	if defaultValue != nil && !required {
+		schema["default"] = defaultValue
+	}
+
+	return schema
+}
+
+func (param *GenericParam) GetDescription() string {
+	return param.Description
+}
+
+func (param *GenericParam) Schema() map[string]interface{} {
+	if param.Default == nil {
+		return typeSchema("", param.Required, false, nil)
+	}
+	return typeSchema("", param.Required, false, param.Default)
+}
+
+func (param *StringParam) GetDescription() string {
+	return param.Description
+}
+
+func (param *StringParam) Schema() map[string]interface{} {
+	return typeSchema("string", param.Required, param.Nullable, param.Default)
+}
+
+func (param *IntParam) GetDescription() string {
+	return param.Description
+}
+
+func (param *IntParam) Schema() map[string]interface{} {
+	return typeSchema("integer", param.Required, param.Nullable, param.Default)
+}
+
+func (param *BoolParam) GetDescription() string {
+	return param.Description
+}
+
+func (param *BoolParam) Schema() map[string]interface{} {
+	return typeSchema("boolean", param.Required, param.Nullable, param.Default)
+}
+
+func (param *float64Param) GetDescription() string {
+	return param.Description
+}
+
+func (param *float64Param) Schema() map[string]interface{} {
+	return typeSchema("number", param.Required, param.Nullable, param.Default)
+}
@@ -3,6 +3,7 @@ package parameters
 import (
 	"encoding/json"
 	"errors"
+	"sort"
 	"strconv"
 )
 
@@ -43,6 +44,25 @@ func NewParametersByPosition(params []Param) *Parameters {
 	return newParameters(ByPosition, params)
 }
 
+// Clone copies the params and every param in them, so the copy can be changed without affecting the original.
+// Nil params clone to empty params by name, as a request treats them.
+func (params *Parameters) Clone() *Parameters {
+	if params == nil {
+		return NewParametersByName(nil)
+	}
+
+	values := make(map[string]Param, len(params.values))
+	for name, param := range params.values {
+		clone, err := param.Clone(nil)
+		if err != nil {
+			clone = param
+		}
+		values[name] = clone
+	}
+
+	return &Parameters{paramType: params.paramType, values: values}
+}
+
 func (params *Parameters) Length() int {
 	return len(params.values)
 }
@@ -67,12 +87,32 @@ func (params *Parameters) Get(key string) Param {
 	return params.values[key]
 }
 
+// Names returns the name of every param, positional params are ordered by position
+func (params *Parameters) Names() []string {
+	names := make([]string, 0, len(params.values))
+	for name := range params.values {
+		names = append(names, name)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		if params.paramType == ByPosition {
+			a, _ := strconv.Atoi(names[i])
+			b, _ := strconv.Atoi(names[j])
+			return a < b
+		}
+		return names[i] < names[j]
+	})
+
+	return names
+}
+
 func (params *Parameters) Serialize() (data json.RawMessage, err error) {
 	if params.paramType == ByName {
 		data, err = json.Marshal(params.values)
 	} else {
 		posMap := make(map[int]Param)
-		largestIndex := 0
+		// Starts below zero so no params serialize to an empty array rather than [null]
+		largestIndex := -1
 		for key, value := range params.values {
 			pos, err := strconv.Atoi(key)
 			if err != nil {
@@ -113,7 +153,7 @@ func (params *Parameters) UnmarshalJSON(jsonData []byte) (err error) {
 		params.values = make(map[string]Param)
 		for index, value := range data {
 			pos := strconv.Itoa(index)
-			params.values[pos] = &GenericParam{pos, value, false, value}
+			params.values[pos] = &GenericParam{Name: pos, Default: value, data: value}
 		}
 		params.paramType = ByPosition
 		break
@@ -125,7 +165,7 @@ func (params *Parameters) UnmarshalJSON(jsonData []byte) (err error) {
 
 		params.values = make(map[string]Param)
 		for key, value := range data {
-			params.values[key] = &GenericParam{key, value, false, value}
+			params.values[key] = &GenericParam{Name: key, Default: value, data: value}
 		}
 
 		params.paramType = ByName
@@ -1,9 +1,14 @@
 package parameters
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 )
 
+// ErrNull is returned by SetData when a required param that is not Nullable is given null
+var ErrNull = errors.New("must not be null")
+
 type Param interface {
 	Clone(json.RawMessage) (Param, error)
 	IsRequired() bool
@@ -11,19 +16,24 @@ type Param interface {
 	GetName() string
 	SetData(json.RawMessage) error
 	GetData() json.RawMessage
+	// IsSet reports whether data was set, false when the param was omitted and holds its default
+	IsSet() bool
+	// IsNull reports whether the data set was null
+	IsNull() bool
 	MarshalJSON() ([]byte, error)
 	UnmarshalJSON([]byte) error
 }
 
 type GenericParam struct {
-	Name     string
-	Default  json.RawMessage
-	Required bool
-	data     json.RawMessage
+	Name        string
+	Default     json.RawMessage
+	Required    bool
+	Description string
+	data        json.RawMessage
 }
 
 func (param *GenericParam) Clone(data json.RawMessage) (Param, error) {
-	clone := GenericParam{param.Name, param.Default, param.Required, param.data}
+	clone := *param
 	if data != nil {
 		err := clone.SetData(data)
 		if err != nil {
@@ -51,6 +61,14 @@ func (param *GenericParam) SetData(message json.RawMessage) (err error) {
 	return
 }
 
+func (param *GenericParam) IsSet() bool {
+	return param.data != nil
+}
+
+func (param *GenericParam) IsNull() bool {
+	return isNull(param.data)
+}
+
 func (param *GenericParam) GetData() json.RawMessage {
 	return param.data
 }
@@ -62,21 +80,21 @@ func (param *GenericParam) MarshalJSON() ([]byte, error) {
 	return param.data, nil
 }
 
-func (param *GenericParam) UnmarshalJSON(jsonData []byte) (err error) {
-	data := param.Default
-	err = json.Unmarshal(jsonData, data)
-	return
+func (param *GenericParam) UnmarshalJSON(jsonData []byte) error {
+	return param.SetData(jsonData)
 }
 
 type StringParam struct {
-	Name     string
-	Default  string
-	Required bool
-	data     json.RawMessage
+	Name        string
+	Default     string
+	Required    bool
+	Nullable    bool
+	Description string
+	data        json.RawMessage
 }
 
 func (param *StringParam) Clone(data json.RawMessage) (Param, error) {
-	clone := StringParam{param.Name, param.Default, param.Required, param.data}
+	clone := *param
 	if data != nil {
 		err := clone.SetData(data)
 		if err != nil {
@@ -100,13 +118,26 @@ func (param *StringParam) GetName() string {
 }
 
 func (param *StringParam) SetData(message json.RawMessage) (err error) {
+	if isNull(message) {
+		param.data, err = nullData(message, param.Nullable, param.Required)
+		return
+	}
+
 	param.data = message
 	_, err = param.GetString()
 	return
 }
 
+func (param *StringParam) IsSet() bool {
+	return param.data != nil
+}
+
+func (param *StringParam) IsNull() bool {
+	return isNull(param.data)
+}
+
 func (param *StringParam) GetData() json.RawMessage {
-	if param.data == nil {
+	if usesDefault(param.data, param.Nullable) {
 		data, _ := json.Marshal(param.Default)
 		return data
 	}
@@ -119,7 +150,7 @@ func (param *StringParam) GetString() (value string, err error) {
 }
 
 func (param *StringParam) MarshalJSON() ([]byte, error) {
-	if param.data == nil {
+	if usesDefault(param.data, param.Nullable) {
 		data, err := json.Marshal(param.Default)
 		if err != nil {
 			return nil, err
@@ -129,21 +160,23 @@ func (param *StringParam) MarshalJSON() ([]byte, error) {
 	return param.data, nil
 }
 
-func (param *StringParam) UnmarshalJSON(jsonData []byte) (err error) {
-	data := param.Default
-	err = json.Unmarshal(jsonData, data)
-	return
+func (param *StringParam) UnmarshalJSON(jsonData []byte) error {
+	return param.SetData(jsonData)
 }
 
 type IntParam struct {
-	Name     string
-	Default  int
-	Required bool
-	data     json.RawMessage
+	Name        string
+	Default     int
+	Required    bool
+	Nullable    bool
+	Description string
+	// Coerce converts compatible representations of the value, see CoerceData
+	Coerce bool
+	data   json.RawMessage
 }
 
 func (param *IntParam) Clone(data json.RawMessage) (Param, error) {
-	clone := IntParam{param.Name, param.Default, param.Required, param.data}
+	clone := *param
 	if data != nil {
 		err := clone.SetData(data)
 		if err != nil {
@@ -167,13 +200,26 @@ func (param *IntParam) GetName() string {
 }
 
 func (param *IntParam) SetData(message json.RawMessage) (err error) {
+	if isNull(message) {
+		param.data, err = nullData(message, param.Nullable, param.Required)
+		return
+	}
+
 	param.data = message
 	_, err = param.GetInt()
 	return
 }
 
+func (param *IntParam) IsSet() bool {
+	return param.data != nil
+}
+
+func (param *IntParam) IsNull() bool {
+	return isNull(param.data)
+}
+
 func (param *IntParam) GetData() json.RawMessage {
-	if param.data == nil {
+	if usesDefault(param.data, param.Nullable) {
 		data, _ := json.Marshal(param.Default)
 		return data
 	}
@@ -186,7 +232,7 @@ func (param *IntParam) GetInt() (value int, err error) {
 }
 
 func (param *IntParam) MarshalJSON() ([]byte, error) {
-	if param.data == nil {
+	if usesDefault(param.data, param.Nullable) {
 		data, err := json.Marshal(param.Default)
 		if err != nil {
 			return nil, err
@@ -196,21 +242,23 @@ func (param *IntParam) MarshalJSON() ([]byte, error) {
 	return param.data, nil
 }
 
-func (param *IntParam) UnmarshalJSON(jsonData []byte) (err error) {
-	data := param.Default
-	err = json.Unmarshal(jsonData, data)
-	return
+func (param *IntParam) UnmarshalJSON(jsonData []byte) error {
+	return param.SetData(jsonData)
 }
 
 type BoolParam struct {
-	Name     string
-	Default  bool
-	Required bool
-	data     json.RawMessage
+	Name        string
+	Default     bool
+	Required    bool
+	Nullable    bool
+	Description string
+	// Coerce converts compatible representations of the value, see CoerceData
+	Coerce bool
+	data   json.RawMessage
 }
 
 func (param *BoolParam) Clone(data json.RawMessage) (Param, error) {
-	clone := BoolParam{param.Name, param.Default, param.Required, param.data}
+	clone := *param
 	if data != nil {
 		err := clone.SetData(data)
 		if err != nil {
@@ -234,13 +282,26 @@ func (param *BoolParam) GetName() string {
 }
 
 func (param *BoolParam) SetData(message json.RawMessage) (err error) {
+	if isNull(message) {
+		param.data, err = nullData(message, param.Nullable, param.Required)
+		return
+	}
+
 	param.data = message
 	_, err = param.GetBool()
 	return
 }
 
+func (param *BoolParam) IsSet() bool {
+	return param.data != nil
+}
+
+func (param *BoolParam) IsNull() bool {
+	return isNull(param.data)
+}
+
 func (param *BoolParam) GetData() json.RawMessage {
-	if param.data == nil {
+	if usesDefault(param.data, param.Nullable) {
 		data, _ := json.Marshal(param.Default)
 		return data
 	}
@@ -253,7 +314,7 @@ func (param *BoolParam) GetBool() (value bool, err error) {
 }
 
 func (param *BoolParam) MarshalJSON() ([]byte, error) {
-	if param.data == nil {
+	if usesDefault(param.data, param.Nullable) {
 		data, err := json.Marshal(param.Default)
 		if err != nil {
 			return nil, err
@@ -263,21 +324,23 @@ func (param *BoolParam) MarshalJSON() ([]byte, error) {
 	return param.data, nil
 }
 
-func (param *BoolParam) UnmarshalJSON(jsonData []byte) (err error) {
-	data := param.Default
-	err = json.Unmarshal(jsonData, data)
-	return
+func (param *BoolParam) UnmarshalJSON(jsonData []byte) error {
+	return param.SetData(jsonData)
 }
 
 type float64Param struct {
-	Name     string
-	Default  float64
-	Required bool
-	data     json.RawMessage
+	Name        string
+	Default     float64
+	Required    bool
+	Nullable    bool
+	Description string
+	// Coerce converts compatible representations of the value, see CoerceData
+	Coerce bool
+	data   json.RawMessage
 }
 
 func (param *float64Param) Clone(data json.RawMessage) (Param, error) {
-	clone := float64Param{param.Name, param.Default, param.Required, param.data}
+	clone := *param
 	if data != nil {
 		err := clone.SetData(data)
 		if err != nil {
@@ -301,13 +364,26 @@ func (param *float64Param) GetName() string {
 }
 
 func (param *float64Param) SetData(message json.RawMessage) (err error) {
+	if isNull(message) {
+		param.data, err = nullData(message, param.Nullable, param.Required)
+		return
+	}
+
 	param.data = message
 	_, err = param.GetFloat64()
 	return
 }
 
+func (param *float64Param) IsSet() bool {
+	return param.data != nil
+}
+
+func (param *float64Param) IsNull() bool {
+	return isNull(param.data)
+}
+
 func (param *float64Param) GetData() json.RawMessage {
-	if param.data == nil {
+	if usesDefault(param.data, param.Nullable) {
 		data, _ := json.Marshal(param.Default)
 		return data
 	}
@@ -320,7 +396,7 @@ func (param *float64Param) GetFloat64() (value float64, err error) {
 }
 
 func (param *float64Param) MarshalJSON() ([]byte, error) {
-	if param.data == nil {
+	if usesDefault(param.data, param.Nullable) {
 		data, err := json.Marshal(param.Default)
 		if err != nil {
 			return nil, err
@@ -330,8 +406,24 @@ func (param *float64Param) MarshalJSON() ([]byte, error) {
 	return param.data, nil
 }
 
-func (param *float64Param) UnmarshalJSON(jsonData []byte) (err error) {
-	data := param.Default
-	err = json.Unmarshal(jsonData, data)
-	return
+func (param *float64Param) UnmarshalJSON(jsonData []byte) error {
+	return param.SetData(jsonData)
+}
+
+func isNull(data json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(data), []byte("null"))
+}
+
+// nullData returns the data a param keeps when given null, only required params that are not Nullable reject it
+func nullData(message json.RawMessage, nullable bool, required bool) (json.RawMessage, error) {
+	if required && !nullable {
+		return nil, ErrNull
+	}
+
+	return message, nil
+}
+
+// usesDefault reports whether a param falls back to its default, optional params that are not Nullable treat null as omitted
+func usesDefault(data json.RawMessage, nullable bool) bool {
+	return data == nil || (!nullable && isNull(data))
 }
@@ -0,0 +1,148 @@
+package parameters
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+// RestParam captures every request param no other declared param is bound to, the positional params after the last
+// declared one or the named params not declared at all. Each is validated by a clone of Element. A method declares at
+// most one RestParam and it must be its last param.
+type RestParam struct {
+	Name string
+	// Element is cloned for every captured param, its name is set to the captured param's name or position
+	Element Param
+	// Required needs at least one param to be captured
+	Required    bool
+	Description string
+	elements    []Param
+	data        json.RawMessage
+}
+
+func (param *RestParam) Clone(data json.RawMessage) (Param, error) {
+	clone := *param
+	if data != nil {
+		err := clone.SetData(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &clone, nil
+}
+
+func (param *RestParam) IsRequired() bool {
+	return param.Required
+}
+
+func (param *RestParam) SetName(newName string) {
+	param.Name = newName
+}
+
+func (param *RestParam) GetName() string {
+	return param.Name
+}
+
+// SetData captures the items of a JSON array, named by their index
+func (param *RestParam) SetData(message json.RawMessage) error {
+	var items []json.RawMessage
+	if err := json.Unmarshal(message, &items); err != nil {
+		return err
+	}
+
+	names := make([]string, len(items))
+	for index := range items {
+		names[index] = strconv.Itoa(index)
+	}
+
+	return param.SetElements(names, items)
+}
+
+// SetElements captures values under names, returning a *ValidationError with every element that was rejected
+func (param *RestParam) SetElements(names []string, values []json.RawMessage) error {
+	if param.Element == nil {
+		return errors.New("RestParam has no Element")
+	}
+
+	var violations []Violation
+	elements := make([]Param, len(values))
+	for index, value := range values {
+		element, err := param.Element.Clone(nil)
+		if err == nil {
+			element.SetName(names[index])
+			err = element.SetData(value)
+		}
+
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			for _, violation := range validationErr.Violations {
+				violations = append(violations, Violation{Path: "/" + names[index] + violation.Path, Message: violation.Message})
+			}
+		} else if err != nil {
+			violations = append(violations, Violation{Path: "/" + names[index], Message: err.Error()})
+		}
+
+		elements[index] = element
+	}
+
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+
+	data, err := json.Marshal(elements)
+	if err != nil {
+		return err
+	}
+
+	param.elements = elements
+	param.data = data
+	return nil
+}
+
+// GetParams returns the captured params in order, each one a clone of Element
+func (param *RestParam) GetParams() []Param {
+	return param.elements
+}
+
+// Len returns the number of captured params
+func (param *RestParam) Len() int {
+	return len(param.elements)
+}
+
+func (param *RestParam) IsSet() bool {
+	return param.data != nil
+}
+
+func (param *RestParam) IsNull() bool {
+	return false
+}
+
+// GetData returns the captured values as a JSON array
+func (param *RestParam) GetData() json.RawMessage {
+	if param.data == nil {
+		return json.RawMessage("[]")
+	}
+	return param.data
+}
+
+func (param *RestParam) MarshalJSON() ([]byte, error) {
+	return param.GetData(), nil
+}
+
+func (param *RestParam) UnmarshalJSON(jsonData []byte) error {
+	return param.SetData(jsonData)
+}
+
+func (param *RestParam) GetDescription() string {
+	return param.Description
+}
+
+func (param *RestParam) Schema() map[string]interface{} {
+	schema := map[string]interface{}{"type": "array"}
+	if describer, ok := param.Element.(Describer); ok {
+		schema["items"] = describer.Schema()
+	}
+
+	return schema
+}
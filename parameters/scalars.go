@@ -0,0 +1,660 @@
+package parameters
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	UUID "github.com/nu7hatch/gouuid"
+)
+
+// TimeParam holds an RFC 3339 timestamp
+type TimeParam struct {
+	Name        string
+	Default     time.Time
+	Required    bool
+	Nullable    bool
+	Description string
+	data        json.RawMessage
+}
+
+func (param *TimeParam) Clone(data json.RawMessage) (Param, error) {
+	clone := *param
+	if data != nil {
+		err := clone.SetData(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &clone, nil
+}
+
+func (param *TimeParam) IsRequired() bool {
+	return param.Required
+}
+
+func (param *TimeParam) SetName(newName string) {
+	param.Name = newName
+}
+
+func (param *TimeParam) GetName() string {
+	return param.Name
+}
+
+func (param *TimeParam) SetData(message json.RawMessage) (err error) {
+	if isNull(message) {
+		param.data, err = nullData(message, param.Nullable, param.Required)
+		return
+	}
+
+	param.data = message
+	_, err = param.GetTime()
+	return
+}
+
+func (param *TimeParam) IsSet() bool {
+	return param.data != nil
+}
+
+func (param *TimeParam) IsNull() bool {
+	return isNull(param.data)
+}
+
+func (param *TimeParam) GetData() json.RawMessage {
+	if usesDefault(param.data, param.Nullable) {
+		data, _ := json.Marshal(param.Default.Format(time.RFC3339Nano))
+		return data
+	}
+	return param.data
+}
+
+// GetTime parses the RFC 3339 timestamp, null is the zero time
+func (param *TimeParam) GetTime() (value time.Time, err error) {
+	var text string
+	data := param.GetData()
+	if err = json.Unmarshal(data, &text); err != nil || isNull(data) {
+		return
+	}
+
+	return time.Parse(time.RFC3339, text)
+}
+
+func (param *TimeParam) MarshalJSON() ([]byte, error) {
+	if usesDefault(param.data, param.Nullable) {
+		return json.Marshal(param.Default.Format(time.RFC3339Nano))
+	}
+	return param.data, nil
+}
+
+func (param *TimeParam) UnmarshalJSON(jsonData []byte) error {
+	return param.SetData(jsonData)
+}
+
+func (param *TimeParam) GetDescription() string {
+	return param.Description
+}
+
+func (param *TimeParam) Schema() map[string]interface{} {
+	schema := typeSchema("string", param.Required, param.Nullable, param.Default.Format(time.RFC3339Nano))
+	schema["format"] = "date-time"
+	return schema
+}
+
+// DurationParam holds a Go duration string such as "1h30m"
+type DurationParam struct {
+	Name        string
+	Default     time.Duration
+	Required    bool
+	Nullable    bool
+	Description string
+	data        json.RawMessage
+}
+
+func (param *DurationParam) Clone(data json.RawMessage) (Param, error) {
+	clone := *param
+	if data != nil {
+		err := clone.SetData(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &clone, nil
+}
+
+func (param *DurationParam) IsRequired() bool {
+	return param.Required
+}
+
+func (param *DurationParam) SetName(newName string) {
+	param.Name = newName
+}
+
+func (param *DurationParam) GetName() string {
+	return param.Name
+}
+
+func (param *DurationParam) SetData(message json.RawMessage) (err error) {
+	if isNull(message) {
+		param.data, err = nullData(message, param.Nullable, param.Required)
+		return
+	}
+
+	param.data = message
+	_, err = param.GetDuration()
+	return
+}
+
+func (param *DurationParam) IsSet() bool {
+	return param.data != nil
+}
+
+func (param *DurationParam) IsNull() bool {
+	return isNull(param.data)
+}
+
+func (param *DurationParam) GetData() json.RawMessage {
+	if usesDefault(param.data, param.Nullable) {
+		data, _ := json.Marshal(param.Default.String())
+		return data
+	}
+	return param.data
+}
+
+// GetDuration parses the duration string, null is zero
+func (param *DurationParam) GetDuration() (value time.Duration, err error) {
+	var text string
+	data := param.GetData()
+	if err = json.Unmarshal(data, &text); err != nil || isNull(data) {
+		return
+	}
+
+	return time.ParseDuration(text)
+}
+
+func (param *DurationParam) MarshalJSON() ([]byte, error) {
+	if usesDefault(param.data, param.Nullable) {
+		return json.Marshal(param.Default.String())
+	}
+	return param.data, nil
+}
+
+func (param *DurationParam) UnmarshalJSON(jsonData []byte) error {
+	return param.SetData(jsonData)
+}
+
+func (param *DurationParam) GetDescription() string {
+	return param.Description
+}
+
+func (param *DurationParam) Schema() map[string]interface{} {
+	return typeSchema("string", param.Required, param.Nullable, param.Default.String())
+}
+
+// BytesParam holds bytes encoded as standard base64
+type BytesParam struct {
+	Name        string
+	Default     []byte
+	Required    bool
+	Nullable    bool
+	Description string
+	data        json.RawMessage
+}
+
+func (param *BytesParam) Clone(data json.RawMessage) (Param, error) {
+	clone := *param
+	if data != nil {
+		err := clone.SetData(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &clone, nil
+}
+
+func (param *BytesParam) IsRequired() bool {
+	return param.Required
+}
+
+func (param *BytesParam) SetName(newName string) {
+	param.Name = newName
+}
+
+func (param *BytesParam) GetName() string {
+	return param.Name
+}
+
+func (param *BytesParam) SetData(message json.RawMessage) (err error) {
+	if isNull(message) {
+		param.data, err = nullData(message, param.Nullable, param.Required)
+		return
+	}
+
+	param.data = message
+	_, err = param.GetBytes()
+	return
+}
+
+func (param *BytesParam) IsSet() bool {
+	return param.data != nil
+}
+
+func (param *BytesParam) IsNull() bool {
+	return isNull(param.data)
+}
+
+func (param *BytesParam) GetData() json.RawMessage {
+	if usesDefault(param.data, param.Nullable) {
+		data, _ := json.Marshal(param.Default)
+		return data
+	}
+	return param.data
+}
+
+// GetBytes decodes the base64 data, null is nil
+func (param *BytesParam) GetBytes() (value []byte, err error) {
+	err = json.Unmarshal(param.GetData(), &value)
+	return
+}
+
+func (param *BytesParam) MarshalJSON() ([]byte, error) {
+	if usesDefault(param.data, param.Nullable) {
+		return json.Marshal(param.Default)
+	}
+	return param.data, nil
+}
+
+func (param *BytesParam) UnmarshalJSON(jsonData []byte) error {
+	return param.SetData(jsonData)
+}
+
+func (param *BytesParam) GetDescription() string {
+	return param.Description
+}
+
+func (param *BytesParam) Schema() map[string]interface{} {
+	var defaultValue interface{}
+	if param.Default != nil {
+		defaultValue = base64.StdEncoding.EncodeToString(param.Default)
+	}
+
+	schema := typeSchema("string", param.Required, param.Nullable, defaultValue)
+	schema["contentEncoding"] = "base64"
+	return schema
+}
+
+// UUIDParam holds a UUID in its hex string form
+type UUIDParam struct {
+	Name        string
+	Default     *UUID.UUID
+	Required    bool
+	Nullable    bool
+	Description string
+	data        json.RawMessage
+}
+
+func (param *UUIDParam) Clone(data json.RawMessage) (Param, error) {
+	clone := *param
+	if data != nil {
+		err := clone.SetData(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &clone, nil
+}
+
+func (param *UUIDParam) IsRequired() bool {
+	return param.Required
+}
+
+func (param *UUIDParam) SetName(newName string) {
+	param.Name = newName
+}
+
+func (param *UUIDParam) GetName() string {
+	return param.Name
+}
+
+func (param *UUIDParam) SetData(message json.RawMessage) (err error) {
+	if isNull(message) {
+		param.data, err = nullData(message, param.Nullable, param.Required)
+		return
+	}
+
+	param.data = message
+	_, err = param.GetUUID()
+	return
+}
+
+func (param *UUIDParam) IsSet() bool {
+	return param.data != nil
+}
+
+func (param *UUIDParam) IsNull() bool {
+	return isNull(param.data)
+}
+
+func (param *UUIDParam) GetData() json.RawMessage {
+	if usesDefault(param.data, param.Nullable) {
+		data, _ := marshalUUID(param.Default)
+		return data
+	}
+	return param.data
+}
+
+// GetUUID parses the UUID, null is nil
+func (param *UUIDParam) GetUUID() (value *UUID.UUID, err error) {
+	var text string
+	data := param.GetData()
+	if err = json.Unmarshal(data, &text); err != nil || isNull(data) {
+		return
+	}
+
+	return UUID.ParseHex(text)
+}
+
+func (param *UUIDParam) MarshalJSON() ([]byte, error) {
+	if usesDefault(param.data, param.Nullable) {
+		return marshalUUID(param.Default)
+	}
+	return param.data, nil
+}
+
+func (param *UUIDParam) UnmarshalJSON(jsonData []byte) error {
+	return param.SetData(jsonData)
+}
+
+func (param *UUIDParam) GetDescription() string {
+	return param.Description
+}
+
+func (param *UUIDParam) Schema() map[string]interface{} {
+	var defaultValue interface{}
+	if param.Default != nil {
+		defaultValue = param.Default.String()
+	}
+
+	schema := typeSchema("string", param.Required, param.Nullable, defaultValue)
+	schema["format"] = "uuid"
+	return schema
+}
+
+// EnumParam holds a string that must be one of Values
+type EnumParam struct {
+	Name        string
+	Default     string
+	Required    bool
+	Nullable    bool
+	Description string
+	// Values is the allowed set
+	Values []string
+	data   json.RawMessage
+}
+
+func (param *EnumParam) Clone(data json.RawMessage) (Param, error) {
+	clone := *param
+	if data != nil {
+		err := clone.SetData(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &clone, nil
+}
+
+func (param *EnumParam) IsRequired() bool {
+	return param.Required
+}
+
+func (param *EnumParam) SetName(newName string) {
+	param.Name = newName
+}
+
+func (param *EnumParam) GetName() string {
+	return param.Name
+}
+
+func (param *EnumParam) SetData(message json.RawMessage) (err error) {
+	if isNull(message) {
+		param.data, err = nullData(message, param.Nullable, param.Required)
+		return
+	}
+
+	param.data = message
+	err = param.checkValue()
+	return
+}
+
+func (param *EnumParam) IsSet() bool {
+	return param.data != nil
+}
+
+func (param *EnumParam) IsNull() bool {
+	return isNull(param.data)
+}
+
+func (param *EnumParam) GetData() json.RawMessage {
+	if usesDefault(param.data, param.Nullable) {
+		data, _ := json.Marshal(param.Default)
+		return data
+	}
+	return param.data
+}
+
+// GetString returns the value, SetData already rejected anything not in Values
+func (param *EnumParam) GetString() (value string, err error) {
+	err = json.Unmarshal(param.GetData(), &value)
+	return
+}
+
+func (param *EnumParam) checkValue() error {
+	value, err := param.GetString()
+	if err != nil {
+		return err
+	}
+
+	for _, allowed := range param.Values {
+		if value == allowed {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("must be one of %s", strings.Join(param.Values, ", "))
+}
+
+func (param *EnumParam) MarshalJSON() ([]byte, error) {
+	if usesDefault(param.data, param.Nullable) {
+		return json.Marshal(param.Default)
+	}
+	return param.data, nil
+}
+
+func (param *EnumParam) UnmarshalJSON(jsonData []byte) error {
+	return param.SetData(jsonData)
+}
+
+func (param *EnumParam) GetDescription() string {
+	return param.Description
+}
+
+func (param *EnumParam) Schema() map[string]interface{} {
+	var defaultValue interface{}
+	if param.Default != "" {
+		defaultValue = param.Default
+	}
+
+	schema := typeSchema("string", param.Required, param.Nullable, defaultValue)
+	schema["enum"] = param.Values
+	return schema
+}
+
+// NumberParam holds a JSON number without rounding it through float64, for amounts that must stay exact
+type NumberParam struct {
+	Name        string
+	Default     json.Number
+	Required    bool
+	Nullable    bool
+	Description string
+	// Coerce converts compatible representations of the value, see CoerceData
+	Coerce bool
+	// Integer rejects numbers written with a fraction or exponent, even integral ones such as 1.0 or 1e3
+	Integer bool
+	data    json.RawMessage
+}
+
+func (param *NumberParam) Clone(data json.RawMessage) (Param, error) {
+	clone := *param
+	if data != nil {
+		err := clone.SetData(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &clone, nil
+}
+
+func (param *NumberParam) IsRequired() bool {
+	return param.Required
+}
+
+func (param *NumberParam) SetName(newName string) {
+	param.Name = newName
+}
+
+func (param *NumberParam) GetName() string {
+	return param.Name
+}
+
+func (param *NumberParam) SetData(message json.RawMessage) (err error) {
+	if isNull(message) {
+		param.data, err = nullData(message, param.Nullable, param.Required)
+		return
+	}
+
+	param.data = message
+	err = param.checkValue()
+	return
+}
+
+func (param *NumberParam) IsSet() bool {
+	return param.data != nil
+}
+
+func (param *NumberParam) IsNull() bool {
+	return isNull(param.data)
+}
+
+func (param *NumberParam) GetData() json.RawMessage {
+	if usesDefault(param.data, param.Nullable) {
+		data, _ := marshalNumber(param.Default)
+		return data
+	}
+	return param.data
+}
+
+// GetNumber returns the number as written by the caller
+func (param *NumberParam) GetNumber() (value json.Number, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(param.GetData()))
+	decoder.UseNumber()
+
+	var decoded interface{}
+	if err = decoder.Decode(&decoded); err != nil || decoded == nil {
+		return
+	}
+
+	value, ok := decoded.(json.Number)
+	if !ok {
+		err = errors.New("must be a number")
+	}
+	return
+}
+
+// GetRat returns the exact value of the number
+func (param *NumberParam) GetRat() (*big.Rat, error) {
+	number, err := param.GetNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := new(big.Rat).SetString(number.String())
+	if !ok {
+		return nil, errors.New("must be a number")
+	}
+	return value, nil
+}
+
+// GetBigInt returns the number as an integer, failing if it has a fraction
+func (param *NumberParam) GetBigInt() (*big.Int, error) {
+	value, err := param.GetRat()
+	if err != nil {
+		return nil, err
+	}
+
+	if !value.IsInt() {
+		return nil, errors.New("must be an integer")
+	}
+	return value.Num(), nil
+}
+
+func (param *NumberParam) checkValue() error {
+	number, err := param.GetNumber()
+	if err == nil && param.Integer && strings.ContainsAny(number.String(), ".eE") {
+		err = errors.New("must be an integer")
+	}
+	return err
+}
+
+func (param *NumberParam) MarshalJSON() ([]byte, error) {
+	if usesDefault(param.data, param.Nullable) {
+		return marshalNumber(param.Default)
+	}
+	return param.data, nil
+}
+
+func (param *NumberParam) UnmarshalJSON(jsonData []byte) error {
+	return param.SetData(jsonData)
+}
+
+func (param *NumberParam) GetDescription() string {
+	return param.Description
+}
+
+func (param *NumberParam) Schema() map[string]interface{} {
+	typeName := "number"
+	if param.Integer {
+		typeName = "integer"
+	}
+
+	var defaultValue interface{}
+	if param.Default != "" {
+		defaultValue = param.Default
+	}
+
+	return typeSchema(typeName, param.Required, param.Nullable, defaultValue)
+}
+
+func marshalUUID(value *UUID.UUID) ([]byte, error) {
+	if value == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(value.String())
+}
+
+// marshalNumber writes an unset number as 0 rather than failing
+func marshalNumber(value json.Number) ([]byte, error) {
+	if value == "" {
+		return []byte("0"), nil
+	}
+	return []byte(value), nil
+}
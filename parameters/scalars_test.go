@@ -0,0 +1,17 @@
+package parameters
+
+import (
+	"testing"
+)
+
+func TestOmittedScalarsWithoutDefault(t *testing.T) {
+	if value, err := (&UUIDParam{Name: "id"}).GetUUID(); value != nil || err != nil {
+		t.Errorf("GetUUID() = %v, %v, want nil, nil", value, err)
+	}
+	if value, err := (&TimeParam{Name: "at"}).GetTime(); !value.IsZero() || err != nil {
+		t.Errorf("GetTime() = %v, %v, want the zero time", value, err)
+	}
+	if value, err := (&DurationParam{Name: "every"}).GetDuration(); value != 0 || err != nil {
+		t.Errorf("GetDuration() = %v, %v, want 0", value, err)
+	}
+}
@@ -0,0 +1,585 @@
+package parameters
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Violation is a single reason a value was rejected, Path is a JSON Pointer into the params
+type Violation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// ValidationError is returned by SetData when the data does not satisfy the param
+type ValidationError struct {
+	Violations []Violation `json:"violations"`
+}
+
+func (err *ValidationError) Error() string {
+	messages := make([]string, len(err.Violations))
+	for index, violation := range err.Violations {
+		messages[index] = violation.Path + ": " + violation.Message
+	}
+
+	return strings.Join(messages, ", ")
+}
+
+// Schema is a compiled JSON Schema supporting the validation keywords of draft 2020-12 except $ref and format
+type Schema struct {
+	raw     map[string]interface{}
+	boolean *bool
+
+	types            []string
+	enum             []interface{}
+	constValue       interface{}
+	hasConst         bool
+	pattern          *regexp.Regexp
+	minLength        *int
+	maxLength        *int
+	minimum          *float64
+	maximum          *float64
+	exclusiveMinimum *float64
+	exclusiveMaximum *float64
+	multipleOf       *float64
+
+	properties           map[string]*Schema
+	required             []string
+	additionalProperties *Schema
+	minProperties        *int
+	maxProperties        *int
+
+	items       *Schema
+	prefixItems []*Schema
+	minItems    *int
+	maxItems    *int
+	uniqueItems bool
+
+	allOf []*Schema
+	anyOf []*Schema
+	oneOf []*Schema
+	not   *Schema
+}
+
+// CompileSchema parses a JSON Schema once so values can be validated against it repeatedly
+func CompileSchema(schemaData json.RawMessage) (*Schema, error) {
+	value, err := decodeValue(schemaData)
+	if err != nil {
+		return nil, err
+	}
+
+	return compileSchema(value, "#")
+}
+
+func compileSchema(value interface{}, location string) (*Schema, error) {
+	if boolean, ok := value.(bool); ok {
+		return &Schema{boolean: &boolean}, nil
+	}
+
+	raw, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: schema must be an object or boolean", location)
+	}
+
+	schema := &Schema{raw: raw}
+	if _, ok := raw["$ref"]; ok {
+		return nil, fmt.Errorf("%s: $ref is not supported", location)
+	}
+
+	switch types := raw["type"].(type) {
+	case nil:
+	case string:
+		schema.types = []string{types}
+	case []interface{}:
+		for _, typeName := range types {
+			name, ok := typeName.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s/type: must be a string or array of strings", location)
+			}
+			schema.types = append(schema.types, name)
+		}
+	default:
+		return nil, fmt.Errorf("%s/type: must be a string or array of strings", location)
+	}
+
+	if enum, ok := raw["enum"]; ok {
+		if schema.enum, ok = enum.([]interface{}); !ok {
+			return nil, fmt.Errorf("%s/enum: must be an array", location)
+		}
+	}
+	schema.constValue, schema.hasConst = raw["const"]
+
+	if pattern, ok := raw["pattern"]; ok {
+		patternString, ok := pattern.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s/pattern: must be a string", location)
+		}
+
+		var err error
+		if schema.pattern, err = regexp.Compile(patternString); err != nil {
+			return nil, fmt.Errorf("%s/pattern: %w", location, err)
+		}
+	}
+
+	var err error
+	integers := map[string]**int{
+		"minLength": &schema.minLength, "maxLength": &schema.maxLength,
+		"minItems": &schema.minItems, "maxItems": &schema.maxItems,
+		"minProperties": &schema.minProperties, "maxProperties": &schema.maxProperties,
+	}
+	for keyword, target := range integers {
+		if *target, err = compileInteger(raw, keyword, location); err != nil {
+			return nil, err
+		}
+	}
+
+	numbers := map[string]**float64{
+		"minimum": &schema.minimum, "maximum": &schema.maximum,
+		"exclusiveMinimum": &schema.exclusiveMinimum, "exclusiveMaximum": &schema.exclusiveMaximum,
+		"multipleOf": &schema.multipleOf,
+	}
+	for keyword, target := range numbers {
+		if *target, err = compileNumber(raw, keyword, location); err != nil {
+			return nil, err
+		}
+	}
+
+	if properties, ok := raw["properties"]; ok {
+		propertyMap, ok := properties.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s/properties: must be an object", location)
+		}
+
+		schema.properties = map[string]*Schema{}
+		for name, property := range propertyMap {
+			if schema.properties[name], err = compileSchema(property, location+"/properties/"+escapePointer(name)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if required, ok := raw["required"]; ok {
+		requiredList, ok := required.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s/required: must be an array", location)
+		}
+
+		for _, name := range requiredList {
+			nameString, ok := name.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s/required: must only contain strings", location)
+			}
+			schema.required = append(schema.required, nameString)
+		}
+	}
+
+	if additional, ok := raw["additionalProperties"]; ok {
+		if schema.additionalProperties, err = compileSchema(additional, location+"/additionalProperties"); err != nil {
+			return nil, err
+		}
+	}
+
+	if items, ok := raw["items"]; ok {
+		if schema.items, err = compileSchema(items, location+"/items"); err != nil {
+			return nil, err
+		}
+	}
+
+	if schema.prefixItems, err = compileSchemaList(raw, "prefixItems", location); err != nil {
+		return nil, err
+	}
+
+	if unique, ok := raw["uniqueItems"].(bool); ok {
+		schema.uniqueItems = unique
+	}
+
+	if schema.allOf, err = compileSchemaList(raw, "allOf", location); err != nil {
+		return nil, err
+	}
+	if schema.anyOf, err = compileSchemaList(raw, "anyOf", location); err != nil {
+		return nil, err
+	}
+	if schema.oneOf, err = compileSchemaList(raw, "oneOf", location); err != nil {
+		return nil, err
+	}
+
+	if not, ok := raw["not"]; ok {
+		if schema.not, err = compileSchema(not, location+"/not"); err != nil {
+			return nil, err
+		}
+	}
+
+	return schema, nil
+}
+
+func compileInteger(raw map[string]interface{}, keyword string, location string) (*int, error) {
+	value, ok := raw[keyword]
+	if !ok {
+		return nil, nil
+	}
+
+	number, ok := toFloat(value)
+	if !ok || number < 0 || number != math.Trunc(number) {
+		return nil, fmt.Errorf("%s/%s: must be a non-negative integer", location, keyword)
+	}
+
+	integer := int(number)
+	return &integer, nil
+}
+
+func compileNumber(raw map[string]interface{}, keyword string, location string) (*float64, error) {
+	value, ok := raw[keyword]
+	if !ok {
+		return nil, nil
+	}
+
+	number, ok := toFloat(value)
+	if !ok {
+		return nil, fmt.Errorf("%s/%s: must be a number", location, keyword)
+	}
+
+	return &number, nil
+}
+
+func compileSchemaList(raw map[string]interface{}, keyword string, location string) ([]*Schema, error) {
+	value, ok := raw[keyword]
+	if !ok {
+		return nil, nil
+	}
+
+	list, ok := value.([]interface{})
+	if !ok || len(list) == 0 {
+		return nil, fmt.Errorf("%s/%s: must be a non-empty array", location, keyword)
+	}
+
+	schemas := make([]*Schema, len(list))
+	for index, item := range list {
+		var err error
+		if schemas[index], err = compileSchema(item, location+"/"+keyword+"/"+strconv.Itoa(index)); err != nil {
+			return nil, err
+		}
+	}
+
+	return schemas, nil
+}
+
+// Map returns the schema as it was given, for documents such as OpenRPC
+func (schema *Schema) Map() map[string]interface{} {
+	if schema.boolean != nil {
+		if *schema.boolean {
+			return map[string]interface{}{}
+		}
+		return map[string]interface{}{"not": map[string]interface{}{}}
+	}
+
+	return schema.raw
+}
+
+// Validate checks data against the schema, returning every violation found with path as the prefix of their paths
+func (schema *Schema) Validate(path string, data json.RawMessage) []Violation {
+	value, err := decodeValue(data)
+	if err != nil {
+		return []Violation{{path, "invalid JSON"}}
+	}
+
+	return schema.validate(path, value)
+}
+
+func (schema *Schema) validate(path string, value interface{}) (violations []Violation) {
+	if schema.boolean != nil {
+		if !*schema.boolean {
+			return []Violation{{path, "no value is allowed"}}
+		}
+		return nil
+	}
+
+	fail := func(format string, args ...interface{}) {
+		violations = append(violations, Violation{path, fmt.Sprintf(format, args...)})
+	}
+
+	if len(schema.types) > 0 {
+		matched := false
+		for _, typeName := range schema.types {
+			if isType(value, typeName) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("must be of type %s", strings.Join(schema.types, " or "))
+			return
+		}
+	}
+
+	if schema.enum != nil {
+		matched := false
+		for _, allowed := range schema.enum {
+			if equalValues(value, allowed) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("must be one of %s", encodeList(schema.enum))
+		}
+	}
+
+	if schema.hasConst && !equalValues(value, schema.constValue) {
+		fail("must be %s", encodeValue(schema.constValue))
+	}
+
+	switch typed := value.(type) {
+	case string:
+		length := utf8.RuneCountInString(typed)
+		if schema.minLength != nil && length < *schema.minLength {
+			fail("must be at least %d characters long", *schema.minLength)
+		}
+		if schema.maxLength != nil && length > *schema.maxLength {
+			fail("must be at most %d characters long", *schema.maxLength)
+		}
+		if schema.pattern != nil && !schema.pattern.MatchString(typed) {
+			fail("must match pattern %s", schema.pattern.String())
+		}
+	case json.Number:
+		number, _ := typed.Float64()
+		if schema.minimum != nil && number < *schema.minimum {
+			fail("must be at least %v", *schema.minimum)
+		}
+		if schema.maximum != nil && number > *schema.maximum {
+			fail("must be at most %v", *schema.maximum)
+		}
+		if schema.exclusiveMinimum != nil && number <= *schema.exclusiveMinimum {
+			fail("must be greater than %v", *schema.exclusiveMinimum)
+		}
+		if schema.exclusiveMaximum != nil && number >= *schema.exclusiveMaximum {
+			fail("must be less than %v", *schema.exclusiveMaximum)
+		}
+		if schema.multipleOf != nil && *schema.multipleOf != 0 {
+			quotient := number / *schema.multipleOf
+			if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+				fail("must be a multiple of %v", *schema.multipleOf)
+			}
+		}
+	case map[string]interface{}:
+		violations = append(violations, schema.validateObject(path, typed)...)
+	case []interface{}:
+		violations = append(violations, schema.validateArray(path, typed)...)
+	}
+
+	for _, subSchema := range schema.allOf {
+		violations = append(violations, subSchema.validate(path, value)...)
+	}
+
+	if schema.anyOf != nil {
+		matched := false
+		for _, subSchema := range schema.anyOf {
+			if len(subSchema.validate(path, value)) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("must match at least one schema in anyOf")
+		}
+	}
+
+	if schema.oneOf != nil {
+		matches := 0
+		for _, subSchema := range schema.oneOf {
+			if len(subSchema.validate(path, value)) == 0 {
+				matches++
+			}
+		}
+		if matches != 1 {
+			fail("must match exactly one schema in oneOf, matched %d", matches)
+		}
+	}
+
+	if schema.not != nil && len(schema.not.validate(path, value)) == 0 {
+		fail("must not match the schema in not")
+	}
+
+	return
+}
+
+func (schema *Schema) validateObject(path string, object map[string]interface{}) (violations []Violation) {
+	for _, name := range schema.required {
+		if _, ok := object[name]; !ok {
+			violations = append(violations, Violation{path + "/" + escapePointer(name), "is required"})
+		}
+	}
+
+	if schema.minProperties != nil && len(object) < *schema.minProperties {
+		violations = append(violations, Violation{path, fmt.Sprintf("must have at least %d properties", *schema.minProperties)})
+	}
+	if schema.maxProperties != nil && len(object) > *schema.maxProperties {
+		violations = append(violations, Violation{path, fmt.Sprintf("must have at most %d properties", *schema.maxProperties)})
+	}
+
+	// Sorted so violations are reported in a stable order
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		propertyPath := path + "/" + escapePointer(name)
+		if property, ok := schema.properties[name]; ok {
+			violations = append(violations, property.validate(propertyPath, object[name])...)
+		} else if schema.additionalProperties != nil {
+			if schema.additionalProperties.boolean != nil && !*schema.additionalProperties.boolean {
+				violations = append(violations, Violation{propertyPath, "is not allowed"})
+			} else {
+				violations = append(violations, schema.additionalProperties.validate(propertyPath, object[name])...)
+			}
+		}
+	}
+
+	return
+}
+
+func (schema *Schema) validateArray(path string, array []interface{}) (violations []Violation) {
+	if schema.minItems != nil && len(array) < *schema.minItems {
+		violations = append(violations, Violation{path, fmt.Sprintf("must have at least %d items", *schema.minItems)})
+	}
+	if schema.maxItems != nil && len(array) > *schema.maxItems {
+		violations = append(violations, Violation{path, fmt.Sprintf("must have at most %d items", *schema.maxItems)})
+	}
+
+	for index, item := range array {
+		itemPath := path + "/" + strconv.Itoa(index)
+		if index < len(schema.prefixItems) {
+			violations = append(violations, schema.prefixItems[index].validate(itemPath, item)...)
+		} else if schema.items != nil {
+			violations = append(violations, schema.items.validate(itemPath, item)...)
+		}
+	}
+
+	if schema.uniqueItems {
+		for i := 0; i < len(array); i++ {
+			for j := i + 1; j < len(array); j++ {
+				if equalValues(array[i], array[j]) {
+					violations = append(violations, Violation{path + "/" + strconv.Itoa(j), "must be unique"})
+				}
+			}
+		}
+	}
+
+	return
+}
+
+// decodeValue keeps numbers as json.Number so integers are not rounded through float64
+func decodeValue(data json.RawMessage) (value interface{}, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err = decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, errors.New("unexpected data after value")
+	}
+
+	return value, nil
+}
+
+func isType(value interface{}, typeName string) bool {
+	switch typeName {
+	case "null":
+		return value == nil
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		float, err := number.Float64()
+		return err == nil && float == math.Trunc(float)
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	}
+
+	return false
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	number, ok := value.(json.Number)
+	if !ok {
+		return 0, false
+	}
+
+	float, err := number.Float64()
+	return float, err == nil
+}
+
+func equalValues(a, b interface{}) bool {
+	switch typedA := a.(type) {
+	case json.Number:
+		floatA, okA := toFloat(typedA)
+		floatB, okB := toFloat(b)
+		return okA && okB && floatA == floatB
+	case map[string]interface{}:
+		typedB, ok := b.(map[string]interface{})
+		if !ok || len(typedA) != len(typedB) {
+			return false
+		}
+		for key, value := range typedA {
+			other, ok := typedB[key]
+			if !ok || !equalValues(value, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		typedB, ok := b.([]interface{})
+		if !ok || len(typedA) != len(typedB) {
+			return false
+		}
+		for index := range typedA {
+			if !equalValues(typedA[index], typedB[index]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return a == b
+}
+
+func encodeValue(value interface{}) string {
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+func encodeList(values []interface{}) string {
+	encoded := make([]string, len(values))
+	for index, value := range values {
+		encoded[index] = encodeValue(value)
+	}
+
+	return strings.Join(encoded, ", ")
+}
+
+func escapePointer(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,95 @@
+package parameters
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSchemaValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		data   string
+		want   []Violation
+	}{
+		{"integer", `{"type": "integer"}`, `42`, nil},
+		{"integer with zero fraction", `{"type": "integer"}`, `1.0`, nil},
+		{"integer with exponent", `{"type": "integer"}`, `1e3`, nil},
+		{"integer beyond float precision", `{"type": "integer"}`, `12345678901234567890`, nil},
+		{"integer with fraction", `{"type": "integer"}`, `1.5`, []Violation{{"", "must be of type integer"}}},
+		{"integer from string", `{"type": "integer"}`, `"1"`, []Violation{{"", "must be of type integer"}}},
+		{"type list", `{"type": ["string", "null"]}`, `null`, nil},
+
+		{"required present", `{"type": "object", "required": ["name"]}`, `{"name": "a"}`, nil},
+		{"required missing", `{"type": "object", "required": ["name", "a/b"]}`, `{}`, []Violation{
+			{"/name", "is required"},
+			{"/a~1b", "is required"},
+		}},
+
+		{"additionalProperties false allows declared", `{"properties": {"a": {}}, "additionalProperties": false}`, `{"a": 1}`, nil},
+		{"additionalProperties false", `{"properties": {"a": {}}, "additionalProperties": false}`, `{"a": 1, "c": 2, "b": 3}`, []Violation{
+			{"/b", "is not allowed"},
+			{"/c", "is not allowed"},
+		}},
+		{"additionalProperties schema", `{"additionalProperties": {"type": "string"}}`, `{"a": 1}`, []Violation{
+			{"/a", "must be of type string"},
+		}},
+
+		{"uniqueItems", `{"uniqueItems": true}`, `[1, 2, 3]`, nil},
+		{"uniqueItems duplicate", `{"uniqueItems": true}`, `[1, 2, 1]`, []Violation{{"/2", "must be unique"}}},
+		{"uniqueItems equal numbers", `{"uniqueItems": true}`, `[1, 1.0]`, []Violation{{"/1", "must be unique"}}},
+		{"uniqueItems equal objects", `{"uniqueItems": true}`, `[{"a": [1]}, {"a": [1]}]`, []Violation{{"/1", "must be unique"}}},
+
+		{"oneOf one match", `{"oneOf": [{"type": "string"}, {"type": "integer"}]}`, `1`, nil},
+		{"oneOf no match", `{"oneOf": [{"type": "string"}, {"type": "integer"}]}`, `true`, []Violation{
+			{"", "must match exactly one schema in oneOf, matched 0"},
+		}},
+		{"oneOf two matches", `{"oneOf": [{"type": "number"}, {"type": "integer"}]}`, `1`, []Violation{
+			{"", "must match exactly one schema in oneOf, matched 2"},
+		}},
+
+		{"nested path", `{"properties": {"items": {"items": {"minLength": 2}}}}`, `{"items": ["ab", "c"]}`, []Violation{
+			{"/items/1", "must be at least 2 characters long"},
+		}},
+		{"invalid JSON", `{}`, `{`, []Violation{{"", "invalid JSON"}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schema, err := CompileSchema(json.RawMessage(test.schema))
+			if err != nil {
+				t.Fatalf("CompileSchema(%s) = %v", test.schema, err)
+			}
+
+			got := schema.Validate("", json.RawMessage(test.data))
+			if len(got) != 0 || len(test.want) != 0 {
+				if !reflect.DeepEqual(got, test.want) {
+					t.Errorf("Validate(%s) = %v, want %v", test.data, got, test.want)
+				}
+			}
+		})
+	}
+}
+
+func TestCompileSchemaErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{"invalid JSON", `{`},
+		{"not an object", `1`},
+		{"ref", `{"$ref": "#/definitions/a"}`},
+		{"nested ref", `{"properties": {"a": {"$ref": "#"}}}`},
+		{"invalid pattern", `{"pattern": "("}`},
+		{"negative minLength", `{"minLength": -1}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := CompileSchema(json.RawMessage(test.schema)); err == nil {
+				t.Errorf("CompileSchema(%s) succeeded, want an error", test.schema)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package parameters
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// SchemaParam validates its data against a JSON Schema compiled once by NewSchemaParam
+type SchemaParam struct {
+	Name        string
+	Default     json.RawMessage
+	Required    bool
+	Description string
+	// Coerce converts compatible representations of the value, see CoerceData
+	Coerce bool
+	schema *Schema
+	data   json.RawMessage
+}
+
+func NewSchemaParam(name string, schema json.RawMessage, required bool) (*SchemaParam, error) {
+	compiled, err := CompileSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SchemaParam{Name: name, Required: required, schema: compiled}, nil
+}
+
+// MustSchemaParam is NewSchemaParam panicking on an invalid schema, for registering methods at startup
+func MustSchemaParam(name string, schema json.RawMessage, required bool) *SchemaParam {
+	param, err := NewSchemaParam(name, schema, required)
+	if err != nil {
+		panic(err)
+	}
+
+	return param
+}
+
+func (param *SchemaParam) Clone(data json.RawMessage) (Param, error) {
+	clone := *param
+	if data != nil {
+		err := clone.SetData(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &clone, nil
+}
+
+func (param *SchemaParam) IsRequired() bool {
+	return param.Required
+}
+
+func (param *SchemaParam) SetName(newName string) {
+	param.Name = newName
+}
+
+func (param *SchemaParam) GetName() string {
+	return param.Name
+}
+
+// SetData returns a *ValidationError with paths relative to the param when the data does not match the schema
+func (param *SchemaParam) SetData(message json.RawMessage) error {
+	if param.schema == nil {
+		return errors.New("SchemaParam must be created with NewSchemaParam")
+	}
+
+	if violations := param.schema.Validate("", message); len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+
+	param.data = message
+	return nil
+}
+
+func (param *SchemaParam) IsSet() bool {
+	return param.data != nil
+}
+
+func (param *SchemaParam) IsNull() bool {
+	return isNull(param.data)
+}
+
+func (param *SchemaParam) GetData() json.RawMessage {
+	if param.data == nil {
+		return param.Default
+	}
+	return param.data
+}
+
+// Decode unmarshals the param's data into value
+func (param *SchemaParam) Decode(value interface{}) error {
+	return json.Unmarshal(param.GetData(), value)
+}
+
+func (param *SchemaParam) MarshalJSON() ([]byte, error) {
+	if param.data == nil {
+		return param.Default, nil
+	}
+	return param.data, nil
+}
+
+func (param *SchemaParam) UnmarshalJSON(jsonData []byte) error {
+	return param.SetData(jsonData)
+}
+
+func (param *SchemaParam) GetDescription() string {
+	return param.Description
+}
+
+func (param *SchemaParam) Schema() map[string]interface{} {
+	schema := map[string]interface{}{}
+	if param.schema != nil {
+		for key, value := range param.schema.Map() {
+			schema[key] = value
+		}
+	}
+
+	if param.Default != nil && !param.Required {
+		var defaultValue interface{}
+		if json.Unmarshal(param.Default, &defaultValue) == nil {
+			schema["default"] = defaultValue
+		}
+	}
+
+	return schema
+}
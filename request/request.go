@@ -21,6 +21,9 @@ type Request struct {
 	jsonRpc     string
 	method      string
 	params      *parameters.Parameters
+
+	// Baka-RPC extension, other JSON-RPC peers ignore it
+	progressToken string
 }
 
 func NewNotification(method string, params *parameters.Parameters) *Request {
@@ -55,6 +58,10 @@ func NewRequest(method, id string, params *parameters.Parameters) *Request {
 	}
 }
 
+func (req *Request) GetType() Types {
+	return req.requestType
+}
+
 func (req *Request) GetMethod() string {
 	return req.method
 }
@@ -71,6 +78,14 @@ func (req *Request) GetId() string {
 	return req.id
 }
 
+func (req *Request) SetProgressToken(token string) {
+	req.progressToken = token
+}
+
+func (req *Request) GetProgressToken() string {
+	return req.progressToken
+}
+
 func (req *Request) Serialize() (message json.RawMessage, err error) {
 	data := map[string]json.RawMessage{}
 
@@ -93,6 +108,11 @@ func (req *Request) Serialize() (message json.RawMessage, err error) {
 		data["id"] = []byte(`"` + req.id + `"`)
 	}
 
+	// Omitted unless progress was requested
+	if req.progressToken != "" {
+		data["progressToken"] = []byte(`"` + req.progressToken + `"`)
+	}
+
 	return json.Marshal(data)
 }
 
@@ -125,6 +145,15 @@ func (req *Request) UnmarshalJSON(jsonData []byte) (err error) {
 		}
 	}
 
+	// Omitted unless progress was requested
+	req.progressToken = ""
+	if jsonReq["progressToken"] != nil {
+		err = json.Unmarshal(jsonReq["progressToken"], &req.progressToken)
+		if err != nil {
+			return err
+		}
+	}
+
 	// May be omitted
 	if jsonReq["params"] != nil {
 		err = json.Unmarshal(jsonReq["params"], &req.params)
@@ -0,0 +1,137 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	UUID "github.com/nu7hatch/gouuid"
+
+	"github.com/bob620/baka-rpc-go/errors"
+	"github.com/bob620/baka-rpc-go/parameters"
+)
+
+// AuthenticateMethod is the reserved method a channel calls to authenticate, it may be called again to re-authenticate
+const AuthenticateMethod = "rpc.authenticate"
+
+// Principal is who a channel authenticated as, its roles are added to the channel's roles
+type Principal struct {
+	ID    string
+	Roles []string
+	// ExpiresAt is when the channel has to authenticate again, zero never expires
+	ExpiresAt time.Time
+	Data      interface{}
+}
+
+// Authenticator validates the params of rpc.authenticate, returning an error rejects the attempt
+type Authenticator func(ctx context.Context, uuid *UUID.UUID, params map[string]parameters.Param) (*Principal, error)
+
+type principalKey struct{}
+
+type authenticateResult struct {
+	ID        string     `json:"id"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// SetAuthenticator requires channels to authenticate before any other method is dispatched, answering Unauthorized
+// until they do. Channels attached afterwards without a principal are disconnected if they have not authenticated
+// within deadline, a zero deadline waits forever.
+func (rpc *BakaRpc) SetAuthenticator(authenticator Authenticator, authParams []parameters.Param, deadline time.Duration) {
+	rpc.authMutex.Lock()
+	rpc.authenticator = authenticator
+	rpc.authDeadline = deadline
+	rpc.authMutex.Unlock()
+
+	rpc.RegisterContextMethod(AuthenticateMethod, authParams, func(ctx context.Context, params map[string]parameters.Param) (json.RawMessage, error) {
+		uuid := ChannelFromContext(ctx)
+
+		principal, err := authenticator(ctx, uuid, params)
+		if err = handlerError(err); err != nil {
+			if rpcErr, ok := err.(*errors.RPCError); ok {
+				return nil, rpcErr
+			}
+			return nil, &errors.RPCError{Code: errors.NewUnauthorized().Code, Message: err.Error()}
+		}
+		if principal == nil {
+			return nil, errors.NewUnauthorized()
+		}
+
+		rpc.SetChannelPrincipal(uuid, principal)
+
+		result := authenticateResult{ID: principal.ID}
+		if !principal.ExpiresAt.IsZero() {
+			result.ExpiresAt = &principal.ExpiresAt
+		}
+		return json.Marshal(result)
+	}, methodPublic())
+}
+
+// ChannelPrincipal attaches the channel already authenticated, e.g. from websocket upgrade headers
+func ChannelPrincipal(principal *Principal) ChannelOption {
+	return func(config *channelConfig) {
+		config.principal = principal
+	}
+}
+
+func methodPublic() MethodOption {
+	return func(method *method) {
+		method.public = true
+	}
+}
+
+func (rpc *BakaRpc) SetChannelPrincipal(uuid *UUID.UUID, principal *Principal) {
+	rpc.chansMutex.Lock()
+	defer rpc.chansMutex.Unlock()
+
+	if rpc.chansOut[uuid] != nil {
+		rpc.chansPrincipal[uuid] = principal
+	}
+}
+
+// GetChannelPrincipal returns the channel's principal, nil if it has not authenticated or it expired
+func (rpc *BakaRpc) GetChannelPrincipal(uuid *UUID.UUID) *Principal {
+	rpc.chansMutex.RLock()
+	principal := rpc.chansPrincipal[uuid]
+	rpc.chansMutex.RUnlock()
+
+	if principal == nil || (!principal.ExpiresAt.IsZero() && time.Now().After(principal.ExpiresAt)) {
+		return nil
+	}
+
+	return principal
+}
+
+// PrincipalFromContext returns the principal of the channel a handler's request arrived on
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+	return principal
+}
+
+func (rpc *BakaRpc) requiresAuthentication() bool {
+	rpc.authMutex.RLock()
+	defer rpc.authMutex.RUnlock()
+
+	return rpc.authenticator != nil
+}
+
+// startAuthDeadline disconnects the channel if it has not authenticated once the deadline passes
+func (rpc *BakaRpc) startAuthDeadline(uuid *UUID.UUID) {
+	rpc.authMutex.RLock()
+	deadline := rpc.authDeadline
+	required := rpc.authenticator != nil
+	rpc.authMutex.RUnlock()
+
+	if !required || deadline <= 0 || rpc.GetChannelPrincipal(uuid) != nil {
+		return
+	}
+
+	time.AfterFunc(deadline, func() {
+		rpc.chansMutex.RLock()
+		authenticated := rpc.chansPrincipal[uuid] != nil
+		rpc.chansMutex.RUnlock()
+
+		if !authenticated {
+			rpc.disconnect(uuid)
+		}
+	})
+}
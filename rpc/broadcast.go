@@ -0,0 +1,118 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	UUID "github.com/nu7hatch/gouuid"
+
+	"github.com/bob620/baka-rpc-go/errors"
+	"github.com/bob620/baka-rpc-go/parameters"
+)
+
+// ChannelFilter selects the channels a fan-out is sent to, a nil filter selects every channel
+type ChannelFilter func(uuid *UUID.UUID) bool
+
+type ChannelResult struct {
+	Channel *UUID.UUID
+	Result  *json.RawMessage
+	Error   *errors.RPCError
+}
+
+// Channels returns the UUIDs of every attached channel
+func (rpc *BakaRpc) Channels() []*UUID.UUID {
+	rpc.chansMutex.RLock()
+	defer rpc.chansMutex.RUnlock()
+
+	uuids := make([]*UUID.UUID, 0, len(rpc.chansOut))
+	for uuid := range rpc.chansOut {
+		uuids = append(uuids, uuid)
+	}
+
+	return uuids
+}
+
+func (rpc *BakaRpc) filterChannels(filter ChannelFilter) []*UUID.UUID {
+	uuids := rpc.Channels()
+	if filter == nil {
+		return uuids
+	}
+
+	filtered := uuids[:0]
+	for _, uuid := range uuids {
+		if filter(uuid) {
+			filtered = append(filtered, uuid)
+		}
+	}
+
+	return filtered
+}
+
+// Broadcast notifies the method on every channel selected by filter, each channel is sent its own copy of params
+func (rpc *BakaRpc) Broadcast(filter ChannelFilter, methodName string, params *parameters.Parameters) {
+	for _, uuid := range rpc.filterChannels(filter) {
+		rpc.NotifyMethod(uuid, methodName, params.Clone())
+	}
+}
+
+// CallAll calls the method on every channel selected by filter concurrently, returning once every channel answered.
+// Each call gets its own copy of params, so call interceptors may change them.
+func (rpc *BakaRpc) CallAll(ctx context.Context, filter ChannelFilter, methodName string, params *parameters.Parameters) []ChannelResult {
+	uuids := rpc.filterChannels(filter)
+	results := make([]ChannelResult, len(uuids))
+
+	wait := sync.WaitGroup{}
+	for index, uuid := range uuids {
+		wait.Add(1)
+		go func(index int, uuid *UUID.UUID, params *parameters.Parameters) {
+			defer wait.Done()
+			res, resErr := rpc.CallMethodContext(ctx, uuid, methodName, params)
+			results[index] = ChannelResult{uuid, res, resErr}
+		}(index, uuid, params.Clone())
+	}
+	wait.Wait()
+
+	return results
+}
+
+// CallQuorum calls the method on every channel selected by filter concurrently, returning the successes once quorum of
+// them succeeded and cancelling the calls still running. A quorum of 1 returns the first success. Each call gets its own
+// copy of params, as in CallAll.
+func (rpc *BakaRpc) CallQuorum(ctx context.Context, filter ChannelFilter, quorum int, methodName string, params *parameters.Parameters) ([]ChannelResult, *errors.RPCError) {
+	uuids := rpc.filterChannels(filter)
+	if quorum <= 0 || quorum > len(uuids) {
+		return nil, errors.NewGenericError("Quorum unreachable")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Buffered so calls finishing after the quorum never block
+	resultChan := make(chan ChannelResult, len(uuids))
+	for _, uuid := range uuids {
+		go func(uuid *UUID.UUID, params *parameters.Parameters) {
+			res, resErr := rpc.CallMethodContext(ctx, uuid, methodName, params)
+			resultChan <- ChannelResult{uuid, res, resErr}
+		}(uuid, params.Clone())
+	}
+
+	var successes []ChannelResult
+	failures := 0
+	for range uuids {
+		result := <-resultChan
+		if result.Error == nil {
+			successes = append(successes, result)
+			if len(successes) >= quorum {
+				return successes, nil
+			}
+		} else {
+			failures++
+			if len(uuids)-failures < quorum {
+				break
+			}
+		}
+	}
+
+	return successes, errors.NewGenericError("Quorum unreachable")
+}
@@ -0,0 +1,46 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/bob620/baka-rpc-go/parameters"
+)
+
+func TestBroadcastAndCallAllWithNilParams(t *testing.T) {
+	client := CreateBakaRpc(nil, nil)
+	servers := []*BakaRpc{CreateBakaRpc(nil, nil), CreateBakaRpc(nil, nil)}
+
+	notified := make(chan struct{}, len(servers))
+	for _, server := range servers {
+		connect(client, server)
+		server.RegisterMethod("ping", []parameters.Param{}, func(params map[string]parameters.Param) (json.RawMessage, error) {
+			return json.RawMessage(`"pong"`), nil
+		})
+		server.RegisterMethod("notice", []parameters.Param{}, func(params map[string]parameters.Param) (json.RawMessage, error) {
+			notified <- struct{}{}
+			return nil, nil
+		})
+	}
+
+	client.Broadcast(nil, "notice", nil)
+	for range servers {
+		waitFor(t, notified)
+	}
+
+	results := client.CallAll(context.Background(), nil, "ping", nil)
+	if len(results) != len(servers) {
+		t.Fatalf("%d results, want one per channel", len(results))
+	}
+	for _, result := range results {
+		if result.Error != nil || result.Result == nil || string(*result.Result) != `"pong"` {
+			t.Errorf("result = %v, %v, want pong", result.Result, result.Error)
+		}
+	}
+
+	successes, resErr := client.CallQuorum(context.Background(), nil, 1, "ping", nil)
+	if resErr != nil || len(successes) != 1 {
+		t.Errorf("quorum = %v, %v, want one success", successes, resErr)
+	}
+}
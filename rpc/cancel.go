@@ -0,0 +1,73 @@
+package rpc
+
+import (
+	"context"
+
+	UUID "github.com/nu7hatch/gouuid"
+
+	"github.com/bob620/baka-rpc-go/parameters"
+	"github.com/bob620/baka-rpc-go/request"
+)
+
+// CancelRequestMethod is the notification sent to abandon a call, its only parameter is the request id
+const CancelRequestMethod = "$/cancelRequest"
+
+type runningKey struct {
+	uuid *UUID.UUID
+	id   string
+}
+
+func (rpc *BakaRpc) trackRequest(uuid *UUID.UUID, req *request.Request) (ctx context.Context, done func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	if req.GetType() == request.NotificationType {
+		return ctx, cancel
+	}
+
+	key := runningKey{uuid, req.GetId()}
+	rpc.runningMutex.Lock()
+	rpc.running[key] = cancel
+	rpc.runningMutex.Unlock()
+
+	return ctx, func() {
+		rpc.runningMutex.Lock()
+		delete(rpc.running, key)
+		rpc.runningMutex.Unlock()
+		cancel()
+	}
+}
+
+func (rpc *BakaRpc) handleCancelRequest(uuid *UUID.UUID, req *request.Request) {
+	var id string
+	if !readParam(req, "id", &id) && !readParam(req, "0", &id) {
+		return
+	}
+
+	rpc.runningMutex.Lock()
+	cancel := rpc.running[runningKey{uuid, id}]
+	rpc.runningMutex.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+}
+
+func (rpc *BakaRpc) cancelChannelRequests(uuid *UUID.UUID) {
+	rpc.runningMutex.Lock()
+	defer rpc.runningMutex.Unlock()
+
+	for key, cancel := range rpc.running {
+		if key.uuid == uuid {
+			cancel()
+		}
+	}
+}
+
+func (rpc *BakaRpc) sendCancelRequest(uuid *UUID.UUID, id string) {
+	if !rpc.peerSupports(uuid, ExtensionCancel) {
+		return
+	}
+
+	rpc.notifyMethod(context.Background(), uuid, CancelRequestMethod, parameters.NewParametersByName([]parameters.Param{
+		&parameters.StringParam{Name: "id", Default: id},
+	}))
+}
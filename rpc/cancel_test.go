@@ -0,0 +1,45 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/bob620/baka-rpc-go/errors"
+	"github.com/bob620/baka-rpc-go/parameters"
+)
+
+func TestCancelRequestCancelsRemoteHandler(t *testing.T) {
+	client, server := CreateBakaRpc(nil, nil), CreateBakaRpc(nil, nil)
+	connect(client, server)
+
+	started := make(chan struct{})
+	stopped := make(chan struct{})
+	server.RegisterContextMethod("wait", []parameters.Param{}, func(ctx context.Context, params map[string]parameters.Param) (json.RawMessage, error) {
+		close(started)
+		<-ctx.Done()
+		close(stopped)
+		return nil, ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results := make(chan *errors.RPCError, 1)
+	go func() {
+		_, resErr := client.CallMethodContext(ctx, nil, "wait", parameters.NewParametersByName(nil))
+		results <- resErr
+	}()
+
+	waitFor(t, started)
+	cancel()
+
+	waitFor(t, stopped)
+	if resErr := <-results; resErr == nil || resErr.Code != errors.NewRequestCancelled().Code {
+		t.Errorf("call = %v, want Request cancelled", resErr)
+	}
+
+	waitUntil(t, func() bool {
+		server.runningMutex.Lock()
+		defer server.runningMutex.Unlock()
+		return len(server.running) == 0
+	})
+}
@@ -0,0 +1,21 @@
+package rpc
+
+import (
+	"context"
+
+	UUID "github.com/nu7hatch/gouuid"
+)
+
+type channelKey struct{}
+type requestIdKey struct{}
+
+// ChannelFromContext returns the UUID of the channel a handler's request arrived on
+func ChannelFromContext(ctx context.Context) *UUID.UUID {
+	uuid, _ := ctx.Value(channelKey{}).(*UUID.UUID)
+	return uuid
+}
+
+func requestIdFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIdKey{}).(string)
+	return id
+}
@@ -0,0 +1,96 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/bob620/baka-rpc-go/openrpc"
+	"github.com/bob620/baka-rpc-go/parameters"
+)
+
+// DiscoverMethod is the reserved method answering with the OpenRPC document of the methods visible to the caller
+const DiscoverMethod = "rpc.discover"
+
+func MethodDescription(description string) MethodOption {
+	return func(method *method) {
+		method.description = description
+	}
+}
+
+// MethodResult describes what the method answers with, methods without one are documented as returning anything
+func MethodResult(name string, schema map[string]interface{}) MethodOption {
+	return func(method *method) {
+		method.result = &openrpc.ContentDescriptor{Name: name, Schema: schema}
+	}
+}
+
+func methodKind(kind string) MethodOption {
+	return func(method *method) {
+		method.kind = kind
+	}
+}
+
+// EnableDiscover registers rpc.discover, describing the application with info
+func (rpc *BakaRpc) EnableDiscover(info openrpc.Info) {
+	rpc.RegisterContextMethod(DiscoverMethod, []parameters.Param{}, func(ctx context.Context, params map[string]parameters.Param) (json.RawMessage, error) {
+		uuid := ChannelFromContext(ctx)
+		return json.Marshal(rpc.describe(info, func(method *method) bool {
+			return rpc.canSee(uuid, method)
+		}))
+	})
+}
+
+// OpenRPCDocument describes every registered method, leaving out the reserved rpc. methods
+func (rpc *BakaRpc) OpenRPCDocument(info openrpc.Info) *openrpc.Document {
+	return rpc.describe(info, func(*method) bool {
+		return true
+	})
+}
+
+// WriteOpenRPC writes the OpenRPC document of every registered method to path
+func (rpc *BakaRpc) WriteOpenRPC(path string, info openrpc.Info) error {
+	return rpc.OpenRPCDocument(info).WriteFile(path)
+}
+
+func (rpc *BakaRpc) describe(info openrpc.Info, include func(*method) bool) *openrpc.Document {
+	document := openrpc.NewDocument(info)
+
+	rpc.methodsMutex.RLock()
+	for name, method := range rpc.methods {
+		if strings.HasPrefix(name, "rpc.") || !include(method) {
+			continue
+		}
+		document.Methods = append(document.Methods, method.describe())
+	}
+	rpc.methodsMutex.RUnlock()
+
+	sort.Slice(document.Methods, func(i, j int) bool {
+		return document.Methods[i].Name < document.Methods[j].Name
+	})
+
+	return document
+}
+
+func (method *method) describe() openrpc.Method {
+	described := openrpc.Method{
+		Name:           method.name,
+		Description:    method.description,
+		Params:         []openrpc.ContentDescriptor{},
+		Result:         method.result,
+		ParamStructure: "either",
+		Kind:           method.kind,
+		Deprecated:     method.deprecated != "",
+	}
+
+	for _, param := range method.params {
+		described.Params = append(described.Params, openrpc.DescribeParam(param))
+	}
+
+	if described.Result == nil {
+		described.Result = &openrpc.ContentDescriptor{Name: "result", Schema: map[string]interface{}{}}
+	}
+
+	return described
+}
@@ -0,0 +1,31 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/bob620/baka-rpc-go/parameters"
+)
+
+// FallbackFunc handles calls of methods that are not registered, given the method name and params as they were sent
+type FallbackFunc func(ctx context.Context, methodName string, params *parameters.Parameters) (json.RawMessage, error)
+
+// HandleMethodNotFound calls fallback instead of answering Method not found when no method is registered under the
+// called name, to proxy calls elsewhere, serve dynamic methods or log them. Returning errors.NewMethodNotFound()
+// answers as if there was no fallback. Methods hidden from the channel by their roles still answer Method not found.
+func (rpc *BakaRpc) HandleMethodNotFound(fallback FallbackFunc) {
+	rpc.methodsMutex.Lock()
+	defer rpc.methodsMutex.Unlock()
+
+	rpc.fallback = fallback
+}
+
+// fallbackMethod wraps fallback as a method, so its errors and cancellation are answered like any handler's
+func fallbackMethod(methodName string, params *parameters.Parameters, fallback FallbackFunc) *method {
+	return &method{
+		name: methodName,
+		methodFunc: func(ctx context.Context, _ map[string]parameters.Param) (json.RawMessage, error) {
+			return fallback(ctx, methodName, params)
+		},
+	}
+}
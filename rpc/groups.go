@@ -0,0 +1,114 @@
+package rpc
+
+import (
+	"context"
+
+	UUID "github.com/nu7hatch/gouuid"
+
+	"github.com/bob620/baka-rpc-go/parameters"
+)
+
+// JoinGroup adds an attached channel to the named group, channels leave every group when they disconnect
+func (rpc *BakaRpc) JoinGroup(group string, uuid *UUID.UUID) {
+	rpc.groupsMutex.Lock()
+	defer rpc.groupsMutex.Unlock()
+
+	// Checked under groupsMutex, a closing channel either is seen as closed here or leaves its groups after joining
+	rpc.chansMutex.RLock()
+	attached := rpc.chansClosed[uuid] != nil
+	rpc.chansMutex.RUnlock()
+
+	if !attached {
+		return
+	}
+
+	if rpc.groups[group] == nil {
+		rpc.groups[group] = map[*UUID.UUID]struct{}{}
+	}
+	rpc.groups[group][uuid] = struct{}{}
+}
+
+func (rpc *BakaRpc) LeaveGroup(group string, uuid *UUID.UUID) {
+	rpc.groupsMutex.Lock()
+	defer rpc.groupsMutex.Unlock()
+
+	delete(rpc.groups[group], uuid)
+	if len(rpc.groups[group]) == 0 {
+		delete(rpc.groups, group)
+	}
+}
+
+// Groups returns the name of every group with at least one member
+func (rpc *BakaRpc) Groups() []string {
+	rpc.groupsMutex.RLock()
+	defer rpc.groupsMutex.RUnlock()
+
+	groups := make([]string, 0, len(rpc.groups))
+	for group := range rpc.groups {
+		groups = append(groups, group)
+	}
+
+	return groups
+}
+
+func (rpc *BakaRpc) GroupMembers(group string) []*UUID.UUID {
+	rpc.groupsMutex.RLock()
+	defer rpc.groupsMutex.RUnlock()
+
+	members := make([]*UUID.UUID, 0, len(rpc.groups[group]))
+	for uuid := range rpc.groups[group] {
+		members = append(members, uuid)
+	}
+
+	return members
+}
+
+func (rpc *BakaRpc) ChannelGroups(uuid *UUID.UUID) []string {
+	rpc.groupsMutex.RLock()
+	defer rpc.groupsMutex.RUnlock()
+
+	var groups []string
+	for group, members := range rpc.groups {
+		if _, ok := members[uuid]; ok {
+			groups = append(groups, group)
+		}
+	}
+
+	return groups
+}
+
+func (rpc *BakaRpc) InGroup(group string, uuid *UUID.UUID) bool {
+	rpc.groupsMutex.RLock()
+	defer rpc.groupsMutex.RUnlock()
+
+	_, ok := rpc.groups[group][uuid]
+	return ok
+}
+
+// NotifyGroup notifies the method on every member of the group
+func (rpc *BakaRpc) NotifyGroup(group string, methodName string, params *parameters.Parameters) {
+	rpc.Broadcast(rpc.groupFilter(group), methodName, params)
+}
+
+// CallGroup calls the method on every member of the group concurrently, returning each member's result
+func (rpc *BakaRpc) CallGroup(ctx context.Context, group string, methodName string, params *parameters.Parameters) []ChannelResult {
+	return rpc.CallAll(ctx, rpc.groupFilter(group), methodName, params)
+}
+
+func (rpc *BakaRpc) groupFilter(group string) ChannelFilter {
+	return func(uuid *UUID.UUID) bool {
+		return rpc.InGroup(group, uuid)
+	}
+}
+
+func (rpc *BakaRpc) leaveAllGroups(uuid *UUID.UUID) {
+	rpc.groupsMutex.Lock()
+	defer rpc.groupsMutex.Unlock()
+
+	for group, members := range rpc.groups {
+		delete(members, uuid)
+		if len(members) == 0 {
+			delete(rpc.groups, group)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+
+	UUID "github.com/nu7hatch/gouuid"
+
+	"github.com/bob620/baka-rpc-go/errors"
+	"github.com/bob620/baka-rpc-go/parameters"
+)
+
+// CallInvoker performs an outgoing call, it is either the next interceptor in the chain or the actual call.
+// Interceptors may run concurrently, once per call, as CallAll and CallQuorum call every channel at once.
+type CallInvoker func(ctx context.Context, channelUuid *UUID.UUID, methodName string, params *parameters.Parameters) (res *json.RawMessage, resErr *errors.RPCError)
+
+// CallInterceptor wraps every outgoing call, it must call invoker to continue the call (or may call it multiple times to retry)
+type CallInterceptor func(ctx context.Context, channelUuid *UUID.UUID, methodName string, params *parameters.Parameters, invoker CallInvoker) (res *json.RawMessage, resErr *errors.RPCError)
+
+// NotifyInvoker performs an outgoing notification, it is either the next interceptor in the chain or the actual send
+type NotifyInvoker func(ctx context.Context, channelUuid *UUID.UUID, methodName string, params *parameters.Parameters)
+
+// NotifyInterceptor wraps every outgoing notification, it must call invoker to continue sending the notification
+type NotifyInterceptor func(ctx context.Context, channelUuid *UUID.UUID, methodName string, params *parameters.Parameters, invoker NotifyInvoker)
+
+// UseCallInterceptors appends interceptors to the outgoing call chain, the first interceptor added is the outermost
+func (rpc *BakaRpc) UseCallInterceptors(interceptors ...CallInterceptor) {
+	rpc.interceptorsMutex.Lock()
+	defer rpc.interceptorsMutex.Unlock()
+
+	rpc.callInterceptors = append(rpc.callInterceptors, interceptors...)
+}
+
+// UseNotifyInterceptors appends interceptors to the outgoing notification chain, the first interceptor added is the outermost
+func (rpc *BakaRpc) UseNotifyInterceptors(interceptors ...NotifyInterceptor) {
+	rpc.interceptorsMutex.Lock()
+	defer rpc.interceptorsMutex.Unlock()
+
+	rpc.notifyInterceptors = append(rpc.notifyInterceptors, interceptors...)
+}
+
+func chainCallInterceptors(interceptors []CallInterceptor, final CallInvoker) CallInvoker {
+	invoker := final
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor := interceptors[i]
+		next := invoker
+		invoker = func(ctx context.Context, channelUuid *UUID.UUID, methodName string, params *parameters.Parameters) (*json.RawMessage, *errors.RPCError) {
+			return interceptor(ctx, channelUuid, methodName, params, next)
+		}
+	}
+
+	return invoker
+}
+
+func chainNotifyInterceptors(interceptors []NotifyInterceptor, final NotifyInvoker) NotifyInvoker {
+	invoker := final
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor := interceptors[i]
+		next := invoker
+		invoker = func(ctx context.Context, channelUuid *UUID.UUID, methodName string, params *parameters.Parameters) {
+			interceptor(ctx, channelUuid, methodName, params, next)
+		}
+	}
+
+	return invoker
+}
@@ -0,0 +1,120 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/bob620/baka-rpc-go/errors"
+	"github.com/bob620/baka-rpc-go/parameters"
+)
+
+type MethodOption func(*method)
+
+type methodResult struct {
+	data json.RawMessage
+	err  error
+}
+
+// MethodTimeout cancels the handler's context and answers with a Timeout error once timeout elapses
+func MethodTimeout(timeout time.Duration) MethodOption {
+	return func(method *method) {
+		method.timeout = timeout
+	}
+}
+
+// MethodMaxConcurrency rejects calls with a Server busy error while max calls of the method are already running
+func MethodMaxConcurrency(max int) MethodOption {
+	return func(method *method) {
+		if max > 0 {
+			method.semaphore = make(chan struct{}, max)
+		} else {
+			method.semaphore = nil
+		}
+	}
+}
+
+// MethodStrict rejects calls with unknown named params or more positional params than the method declares
+func MethodStrict() MethodOption {
+	return func(method *method) {
+		method.strict = true
+	}
+}
+
+// SetStrictParams makes every method strict, as if registered with MethodStrict
+func (rpc *BakaRpc) SetStrictParams(strict bool) {
+	rpc.methodsMutex.Lock()
+	defer rpc.methodsMutex.Unlock()
+
+	rpc.strictParams = strict
+}
+
+func (method *method) call(ctx context.Context, params map[string]parameters.Param) (json.RawMessage, *errors.RPCError) {
+	// Cancelled while still queued for a worker
+	if ctx.Err() != nil {
+		return nil, errors.NewRequestCancelled()
+	}
+
+	if method.semaphore != nil {
+		select {
+		case method.semaphore <- struct{}{}:
+		default:
+			return nil, errors.NewServerBusy()
+		}
+	}
+
+	// Without a timeout the handler is only cancelled through its context, so it runs on the caller's worker
+	if method.timeout <= 0 {
+		if method.semaphore != nil {
+			defer func() { <-method.semaphore }()
+		}
+
+		data, err := method.methodFunc(ctx, params)
+		if ctx.Err() != nil {
+			return nil, errors.NewRequestCancelled()
+		}
+		return methodResult{data, handlerError(err)}.reply()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, method.timeout)
+	defer cancel()
+
+	// The handler keeps running after a timeout, so it holds its concurrency slot until it actually returns
+	result := make(chan methodResult, 1)
+	go func() {
+		if method.semaphore != nil {
+			defer func() { <-method.semaphore }()
+		}
+
+		data, err := method.methodFunc(ctx, params)
+		result <- methodResult{data, handlerError(err)}
+	}()
+
+	select {
+	case res := <-result:
+		return res.reply()
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, errors.NewTimeout()
+		}
+		return nil, errors.NewRequestCancelled()
+	}
+}
+
+func (res methodResult) reply() (json.RawMessage, *errors.RPCError) {
+	if rpcErr, ok := res.err.(*errors.RPCError); ok {
+		return nil, rpcErr
+	}
+	if res.err != nil {
+		return nil, errors.NewGenericError(res.err.Error())
+	}
+	return res.data, nil
+}
+
+// handlerError treats a nil *RPCError returned as an error as no error, instead of failing the call with it
+func handlerError(err error) error {
+	if rpcErr, ok := err.(*errors.RPCError); ok && rpcErr == nil {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,211 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	UUID "github.com/nu7hatch/gouuid"
+
+	"github.com/bob620/baka-rpc-go/errors"
+	"github.com/bob620/baka-rpc-go/parameters"
+)
+
+// HandshakeMethod is the reserved method Baka-RPC peers call on each other to agree on a protocol version and extensions
+const HandshakeMethod = "rpc.handshake"
+
+// ProtocolVersion is the version of the Baka-RPC extensions to JSON-RPC 2.0 this side speaks
+const ProtocolVersion = 1
+
+// Extensions the application can offer when it supports them on top of its channels, such as compressing the messages
+// of channels that agreed on ExtensionCompression
+const (
+	ExtensionCompression = "compression"
+	ExtensionBinary      = "binary"
+	ExtensionBatching    = "batching"
+)
+
+// DefaultNegotiationTimeout is how long a handshake waits for an answer before falling back to plain JSON-RPC 2.0
+const DefaultNegotiationTimeout = 5 * time.Second
+
+// Negotiation is what a channel agreed on, Protocol is 0 and there are no extensions for plain JSON-RPC 2.0 peers
+type Negotiation struct {
+	Protocol   int      `json:"protocol"`
+	Extensions []string `json:"extensions"`
+}
+
+type negotiationConfig struct {
+	enabled    bool
+	timeout    time.Duration
+	extensions []string
+	handle     func(uuid *UUID.UUID, negotiation Negotiation)
+}
+
+// EnableNegotiation registers rpc.handshake and starts a handshake on every channel attached afterwards, offering the
+// built in extensions and extensions. Peers not answering within timeout, or answering with an error as non Baka-RPC
+// peers do, are treated as plain JSON-RPC 2.0. A zero timeout uses DefaultNegotiationTimeout.
+func (rpc *BakaRpc) EnableNegotiation(timeout time.Duration, extensions ...string) {
+	if timeout <= 0 {
+		timeout = DefaultNegotiationTimeout
+	}
+
+	rpc.negotiationMutex.Lock()
+	rpc.negotiation.enabled = true
+	rpc.negotiation.timeout = timeout
+	rpc.negotiation.extensions = append([]string(nil), extensions...)
+	rpc.negotiationMutex.Unlock()
+
+	rpc.RegisterContextMethod(HandshakeMethod, []parameters.Param{
+		&parameters.IntParam{Name: "protocol", Required: true},
+		parameters.MustSchemaParam("extensions", json.RawMessage(`{"type": "array", "items": {"type": "string"}}`), false),
+	}, func(ctx context.Context, params map[string]parameters.Param) (json.RawMessage, error) {
+		remote := Negotiation{}
+		remote.Protocol, _ = params["protocol"].(*parameters.IntParam).GetInt()
+		if params["extensions"].IsSet() {
+			_ = params["extensions"].(*parameters.SchemaParam).Decode(&remote.Extensions)
+		}
+
+		agreed := rpc.agree(remote)
+		rpc.setNegotiation(ChannelFromContext(ctx), agreed)
+		return json.Marshal(agreed)
+	}, methodPublic())
+}
+
+// HandleNegotiated is called whenever what a channel agreed on changes, after its handshake completed or fell back
+func (rpc *BakaRpc) HandleNegotiated(handle func(uuid *UUID.UUID, negotiation Negotiation)) {
+	rpc.negotiationMutex.Lock()
+	defer rpc.negotiationMutex.Unlock()
+
+	rpc.negotiation.handle = handle
+}
+
+// Negotiate performs a handshake with the channel, for channels attached before EnableNegotiation. Peers answering
+// with an error are plain JSON-RPC 2.0, which is not an error.
+func (rpc *BakaRpc) Negotiate(ctx context.Context, uuid *UUID.UUID) (Negotiation, *errors.RPCError) {
+	if uuid == nil {
+		uuid = rpc.defaultChannel()
+	}
+
+	offer := Negotiation{Protocol: ProtocolVersion, Extensions: rpc.Extensions()}
+	params := parameters.NewParametersByName([]parameters.Param{
+		&parameters.IntParam{Name: "protocol", Default: offer.Protocol},
+		&parameters.GenericParam{Name: "extensions", Default: mustMarshal(offer.Extensions)},
+	})
+
+	// Protocol calls skip the interceptors, they are not the application's
+	res, resErr := rpc.callMethod(ctx, uuid, HandshakeMethod, params)
+
+	rpc.chansMutex.RLock()
+	attached := rpc.chansOut[uuid] != nil
+	rpc.chansMutex.RUnlock()
+	if resErr != nil && !attached {
+		return Negotiation{}, resErr
+	}
+
+	agreed := Negotiation{}
+	remote := Negotiation{}
+	if resErr == nil && res != nil && json.Unmarshal(*res, &remote) == nil {
+		agreed = rpc.agree(remote)
+	}
+
+	rpc.setNegotiation(uuid, agreed)
+	return agreed, nil
+}
+
+// GetChannelNegotiation returns what the channel agreed on, nil until its handshake completed or fell back
+func (rpc *BakaRpc) GetChannelNegotiation(uuid *UUID.UUID) *Negotiation {
+	rpc.chansMutex.RLock()
+	defer rpc.chansMutex.RUnlock()
+
+	negotiation := rpc.chansNegotiation[uuid]
+	if negotiation == nil {
+		return nil
+	}
+
+	copied := Negotiation{negotiation.Protocol, append([]string(nil), negotiation.Extensions...)}
+	return &copied
+}
+
+// ChannelHasExtension reports whether the channel agreed on extension
+func (rpc *BakaRpc) ChannelHasExtension(uuid *UUID.UUID, extension string) bool {
+	negotiation := rpc.GetChannelNegotiation(uuid)
+	return negotiation != nil && containsString(negotiation.Extensions, extension)
+}
+
+// peerSupports reports whether the channel may be sent extension, assuming it can until a handshake says otherwise
+func (rpc *BakaRpc) peerSupports(uuid *UUID.UUID, extension string) bool {
+	negotiation := rpc.GetChannelNegotiation(uuid)
+	return negotiation == nil || containsString(negotiation.Extensions, extension)
+}
+
+func (rpc *BakaRpc) startNegotiation(uuid *UUID.UUID) {
+	rpc.negotiationMutex.RLock()
+	enabled := rpc.negotiation.enabled
+	timeout := rpc.negotiation.timeout
+	rpc.negotiationMutex.RUnlock()
+
+	if !enabled {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		_, _ = rpc.Negotiate(ctx, uuid)
+	}()
+}
+
+// agree returns the lower protocol version and the extensions offered by both sides, in the order of Extensions
+func (rpc *BakaRpc) agree(remote Negotiation) Negotiation {
+	if remote.Protocol <= 0 {
+		return Negotiation{}
+	}
+
+	agreed := Negotiation{Protocol: remote.Protocol, Extensions: []string{}}
+	if ProtocolVersion < agreed.Protocol {
+		agreed.Protocol = ProtocolVersion
+	}
+
+	for _, extension := range rpc.Extensions() {
+		if containsString(remote.Extensions, extension) {
+			agreed.Extensions = append(agreed.Extensions, extension)
+		}
+	}
+
+	return agreed
+}
+
+// setNegotiation stores what the channel agreed on, never falling back from a completed handshake since both sides
+// may start one at once and either may time out
+func (rpc *BakaRpc) setNegotiation(uuid *UUID.UUID, negotiation Negotiation) {
+	rpc.chansMutex.Lock()
+	previous := rpc.chansNegotiation[uuid]
+	changed := rpc.chansOut[uuid] != nil && (previous == nil || previous.Protocol == 0 && negotiation.Protocol > 0)
+	if changed {
+		rpc.chansNegotiation[uuid] = &negotiation
+	}
+	rpc.chansMutex.Unlock()
+
+	rpc.negotiationMutex.RLock()
+	handle := rpc.negotiation.handle
+	rpc.negotiationMutex.RUnlock()
+
+	if changed && handle != nil {
+		handle(uuid, negotiation)
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, existing := range values {
+		if existing == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func mustMarshal(value interface{}) json.RawMessage {
+	data, _ := json.Marshal(value)
+	return data
+}
@@ -0,0 +1,95 @@
+package rpc
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	UUID "github.com/nu7hatch/gouuid"
+
+	"github.com/bob620/baka-rpc-go/parameters"
+)
+
+func waitNegotiated(t *testing.T, negotiated <-chan Negotiation) Negotiation {
+	t.Helper()
+
+	select {
+	case negotiation := <-negotiated:
+		return negotiation
+	case <-time.After(time.Second):
+		t.Fatal("handshake did not complete")
+		return Negotiation{}
+	}
+}
+
+func TestNegotiationFallsBackForPlainPeer(t *testing.T) {
+	client, server := CreateBakaRpc(nil, nil), CreateBakaRpc(nil, nil)
+
+	negotiated := make(chan Negotiation, 1)
+	client.HandleNegotiated(func(uuid *UUID.UUID, negotiation Negotiation) {
+		negotiated <- negotiation
+	})
+	client.EnableNegotiation(time.Second)
+
+	// The server never registered rpc.handshake, so it answers with Method not found
+	channel, _ := connect(client, server)
+	if negotiation := waitNegotiated(t, negotiated); negotiation.Protocol != 0 || len(negotiation.Extensions) != 0 {
+		t.Fatalf("negotiated %+v, want plain JSON-RPC 2.0", negotiation)
+	}
+
+	if client.ChannelHasExtension(channel, ExtensionSubscriptions) {
+		t.Error("plain peer reported as supporting subscriptions")
+	}
+	_, resErr := client.Subscribe(context.Background(), channel, "ticks", parameters.NewParametersByName(nil))
+	if resErr == nil || resErr.Message != "Subscriptions not supported" {
+		t.Errorf("Subscribe = %v, want Subscriptions not supported", resErr)
+	}
+}
+
+func TestNegotiationFallsBackOnTimeout(t *testing.T) {
+	client := CreateBakaRpc(nil, nil)
+
+	// A peer that never answers
+	clientOut := make(chan []byte)
+	go func() {
+		for range clientOut {
+		}
+	}()
+
+	negotiated := make(chan Negotiation, 1)
+	client.HandleNegotiated(func(uuid *UUID.UUID, negotiation Negotiation) {
+		negotiated <- negotiation
+	})
+	client.EnableNegotiation(10 * time.Millisecond)
+
+	channel := client.AddChannels(make(chan []byte), clientOut)
+	if negotiation := waitNegotiated(t, negotiated); negotiation.Protocol != 0 {
+		t.Fatalf("negotiated %+v, want plain JSON-RPC 2.0", negotiation)
+	}
+	if client.GetChannelNegotiation(channel) == nil {
+		t.Error("no negotiation stored after falling back")
+	}
+}
+
+func TestNegotiationAgreesOnSharedExtensions(t *testing.T) {
+	client, server := CreateBakaRpc(nil, nil), CreateBakaRpc(nil, nil)
+
+	negotiated := make(chan Negotiation, 2)
+	client.HandleNegotiated(func(uuid *UUID.UUID, negotiation Negotiation) {
+		negotiated <- negotiation
+	})
+	client.EnableNegotiation(time.Second, ExtensionBatching)
+	server.EnableNegotiation(time.Second, ExtensionCompression)
+
+	channel, _ := connect(client, server)
+	negotiation := waitNegotiated(t, negotiated)
+
+	want := []string{ExtensionCancel, ExtensionProgress, ExtensionSubscriptions, ExtensionStreaming}
+	if negotiation.Protocol != ProtocolVersion || !reflect.DeepEqual(negotiation.Extensions, want) {
+		t.Fatalf("negotiated %+v, want protocol %d with %v", negotiation, ProtocolVersion, want)
+	}
+	if client.ChannelHasExtension(channel, ExtensionBatching) || client.ChannelHasExtension(channel, ExtensionCompression) {
+		t.Error("agreed on an extension only one side offered")
+	}
+}
@@ -0,0 +1,216 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	errs "errors"
+	"strconv"
+
+	"github.com/bob620/baka-rpc-go/errors"
+	"github.com/bob620/baka-rpc-go/parameters"
+)
+
+// Coercion is a param value converted for a lenient caller, Path is a JSON Pointer into the params
+type Coercion struct {
+	Method string
+	Path   string
+	From   json.RawMessage
+	To     json.RawMessage
+}
+
+// MethodCoerce coerces every param of the method implementing parameters.Coercible, as if declared with Coerce
+func MethodCoerce() MethodOption {
+	return func(method *method) {
+		method.coerce = true
+	}
+}
+
+// HandleCoercion is called with every param value coerced before its handler runs, for debugging lenient callers
+func (rpc *BakaRpc) HandleCoercion(handle func(ctx context.Context, coercion Coercion)) {
+	rpc.methodsMutex.Lock()
+	defer rpc.methodsMutex.Unlock()
+
+	rpc.coercionHandle = handle
+}
+
+// MethodParamAlias also accepts a named param under each of aliases, such as the name it had before being renamed
+func MethodParamAlias(name string, aliases ...string) MethodOption {
+	return func(method *method) {
+		if method.aliases == nil {
+			method.aliases = map[string][]string{}
+		}
+		method.aliases[name] = append(method.aliases[name], aliases...)
+	}
+}
+
+// bindParams clones the method's declared params and sets them from the request, every param that is missing or
+// rejects its data is reported in the Invalid params error data. Strict also reports params the method does not declare.
+func bindParams(method *method, reqParams *parameters.Parameters, strict bool) (map[string]parameters.Param, []Coercion, *errors.RPCError) {
+	var violations []parameters.Violation
+	var coercions []Coercion
+	if strict {
+		violations = unknownParams(method, reqParams)
+	}
+	sanitizedParams := map[string]parameters.Param{}
+
+	for index, param := range method.params {
+		name := param.GetName()
+		// Clone to keep the default values of every param not in the request
+		sanitizedParams[name], _ = param.Clone(nil)
+
+		if rest, ok := sanitizedParams[name].(*parameters.RestParam); ok {
+			restViolations, restCoercions := bindRest(rest, method, reqParams)
+			violations = append(violations, restViolations...)
+			coercions = append(coercions, restCoercions...)
+			continue
+		}
+
+		var reqParam parameters.Param
+		switch reqParams.GetType() {
+		case parameters.ByName:
+			reqParam = reqParams.Get(name)
+			for _, alias := range method.aliases[name] {
+				if aliased := reqParams.Get(alias); aliased != nil && reqParam != nil {
+					violations = append(violations, parameters.Violation{Path: "/" + alias, Message: "is an alias of /" + name + ", which was also given"})
+				} else if aliased != nil {
+					reqParam = aliased
+				}
+			}
+		case parameters.ByPosition:
+			reqParam = reqParams.Get(strconv.Itoa(index))
+		}
+
+		if reqParam == nil {
+			if param.IsRequired() {
+				violations = append(violations, parameters.Violation{Path: "/" + name, Message: "is required"})
+			}
+			continue
+		}
+
+		data := reqParam.GetData()
+		if coerced, ok := coerceParam(method, param, data); ok {
+			coercions = append(coercions, Coercion{Method: method.name, Path: "/" + name, From: data, To: coerced})
+			data = coerced
+		}
+
+		if err := sanitizedParams[name].SetData(data); err != nil {
+			violations = append(violations, paramViolations(name, err)...)
+		}
+	}
+
+	if len(violations) > 0 {
+		return nil, nil, errors.NewInvalidParams().WithData(parameters.ValidationError{Violations: violations})
+	}
+
+	return sanitizedParams, coercions, nil
+}
+
+// bindRest sets the rest param from every request param not bound to another declared param
+func bindRest(rest *parameters.RestParam, method *method, reqParams *parameters.Parameters) (violations []parameters.Violation, coercions []Coercion) {
+	names := extraParams(method, reqParams)
+	if len(names) == 0 {
+		if rest.IsRequired() {
+			violations = []parameters.Violation{{Path: "/" + rest.GetName(), Message: "is required"}}
+		}
+		return
+	}
+
+	values := make([]json.RawMessage, len(names))
+	for index, name := range names {
+		values[index] = reqParams.Get(name).GetData()
+		if coerced, ok := coerceParam(method, rest.Element, values[index]); ok {
+			coercions = append(coercions, Coercion{Method: method.name, Path: "/" + name, From: values[index], To: coerced})
+			values[index] = coerced
+		}
+	}
+
+	// Element paths are already those of the request params
+	err := rest.SetElements(names, values)
+	var validationErr *parameters.ValidationError
+	if errs.As(err, &validationErr) {
+		violations = validationErr.Violations
+	} else if err != nil {
+		violations = []parameters.Violation{{Path: "/" + rest.GetName(), Message: err.Error()}}
+	}
+
+	return
+}
+
+// coerceParam converts data for params declared with Coerce, or any coercible param of a method registered with
+// MethodCoerce
+func coerceParam(method *method, param parameters.Param, data json.RawMessage) (json.RawMessage, bool) {
+	coercible, ok := param.(parameters.Coercible)
+	if !ok || !(method.coerce || coercible.CoercionEnabled()) {
+		return data, false
+	}
+
+	return coercible.CoerceData(data)
+}
+
+// unknownParams reports the named params the method does not declare, or the positional ones past its last param.
+// Methods with a rest param have none.
+func unknownParams(method *method, reqParams *parameters.Parameters) (violations []parameters.Violation) {
+	for _, param := range method.params {
+		if _, ok := param.(*parameters.RestParam); ok {
+			return nil
+		}
+	}
+
+	message := "is not a known param"
+	if reqParams.GetType() == parameters.ByPosition {
+		message = "is past the last param"
+	}
+
+	for _, name := range extraParams(method, reqParams) {
+		violations = append(violations, parameters.Violation{Path: "/" + name, Message: message})
+	}
+
+	return
+}
+
+// extraParams returns the names of the request params no declared param other than a rest param is bound to
+func extraParams(method *method, reqParams *parameters.Parameters) (extra []string) {
+	declared := map[string]bool{}
+	positions := 0
+	for _, param := range method.params {
+		if _, ok := param.(*parameters.RestParam); !ok {
+			declared[param.GetName()] = true
+			for _, alias := range method.aliases[param.GetName()] {
+				declared[alias] = true
+			}
+			positions++
+		}
+	}
+
+	for _, name := range reqParams.Names() {
+		switch reqParams.GetType() {
+		case parameters.ByName:
+			if !declared[name] {
+				extra = append(extra, name)
+			}
+		case parameters.ByPosition:
+			if index, _ := strconv.Atoi(name); index >= positions {
+				extra = append(extra, name)
+			}
+		}
+	}
+
+	return
+}
+
+// paramViolations prefixes the paths of a param's violations with its name
+func paramViolations(name string, err error) []parameters.Violation {
+	path := "/" + name
+
+	var validationErr *parameters.ValidationError
+	if !errs.As(err, &validationErr) {
+		return []parameters.Violation{{Path: path, Message: err.Error()}}
+	}
+
+	violations := make([]parameters.Violation, len(validationErr.Violations))
+	for index, violation := range validationErr.Violations {
+		violations[index] = parameters.Violation{Path: path + violation.Path, Message: violation.Message}
+	}
+
+	return violations
+}
@@ -0,0 +1,95 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+
+	UUID "github.com/nu7hatch/gouuid"
+
+	"github.com/bob620/baka-rpc-go/errors"
+	"github.com/bob620/baka-rpc-go/parameters"
+	"github.com/bob620/baka-rpc-go/request"
+)
+
+// ProgressMethod is the notification carrying a progress value for the call holding the matching progress token
+const ProgressMethod = "$/progress"
+
+// ProgressFunc receives progress values on the caller side, it runs on the channel's reader so it must not block
+type ProgressFunc func(value json.RawMessage)
+
+// ReportProgress sends a progress value to the caller, it errors once the call is cancelled or timed out
+type ReportProgress func(value interface{}) error
+
+type progressFuncKey struct{}
+type reportProgressKey struct{}
+
+// CallMethodWithProgress works like CallMethodContext, onProgress receives every value the handler reports before the final result.
+// Channels that negotiated without the progress extension are not asked for progress, so onProgress is never called.
+func (rpc *BakaRpc) CallMethodWithProgress(ctx context.Context, channelUuid *UUID.UUID, methodName string, params *parameters.Parameters, onProgress ProgressFunc) (res *json.RawMessage, resErr *errors.RPCError) {
+	return rpc.CallMethodContext(context.WithValue(ctx, progressFuncKey{}, onProgress), channelUuid, methodName, params)
+}
+
+// ProgressReporter returns the handler's ReportProgress, it does nothing if the caller did not ask for progress
+func ProgressReporter(ctx context.Context) ReportProgress {
+	if report, ok := ctx.Value(reportProgressKey{}).(ReportProgress); ok {
+		return report
+	}
+
+	return func(interface{}) error {
+		return nil
+	}
+}
+
+func (rpc *BakaRpc) withProgressReporter(ctx context.Context, uuid *UUID.UUID, req *request.Request) context.Context {
+	token := req.GetProgressToken()
+	if token == "" {
+		return ctx
+	}
+
+	return context.WithValue(ctx, reportProgressKey{}, ReportProgress(func(value interface{}) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		// Sent synchronously so progress always arrives before the final response
+		return rpc.sendNotification(uuid, ProgressMethod, map[string]interface{}{"token": token, "value": value})
+	}))
+}
+
+func (rpc *BakaRpc) trackProgress(ctx context.Context, uuid *UUID.UUID, req *request.Request) (done func()) {
+	onProgress, ok := ctx.Value(progressFuncKey{}).(ProgressFunc)
+	if !ok || onProgress == nil || !rpc.peerSupports(uuid, ExtensionProgress) {
+		return func() {}
+	}
+
+	token := req.GetId()
+	req.SetProgressToken(token)
+	key := runningKey{uuid, token}
+
+	rpc.progressMutex.Lock()
+	rpc.progressFuncs[key] = onProgress
+	rpc.progressMutex.Unlock()
+
+	return func() {
+		rpc.progressMutex.Lock()
+		delete(rpc.progressFuncs, key)
+		rpc.progressMutex.Unlock()
+	}
+}
+
+// handleProgress only accepts progress from the channel the call was sent on
+func (rpc *BakaRpc) handleProgress(uuid *UUID.UUID, req *request.Request) {
+	var token string
+	var value json.RawMessage
+	if !readParam(req, "token", &token) || !readParam(req, "value", &value) {
+		return
+	}
+
+	rpc.progressMutex.RLock()
+	onProgress := rpc.progressFuncs[runningKey{uuid, token}]
+	rpc.progressMutex.RUnlock()
+
+	if onProgress != nil {
+		onProgress(value)
+	}
+}
@@ -0,0 +1,55 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/bob620/baka-rpc-go/parameters"
+)
+
+func TestProgressArrivesBeforeResult(t *testing.T) {
+	client, server := CreateBakaRpc(nil, nil), CreateBakaRpc(nil, nil)
+	connect(client, server)
+
+	server.RegisterContextMethod("work", []parameters.Param{}, func(ctx context.Context, params map[string]parameters.Param) (json.RawMessage, error) {
+		report := ProgressReporter(ctx)
+		for percent := 25; percent <= 100; percent += 25 {
+			if err := report(percent); err != nil {
+				return nil, err
+			}
+		}
+		return json.RawMessage(`"done"`), nil
+	})
+
+	// Called on the channel's reader, so no locking is needed before the call returns
+	var values []string
+	res, resErr := client.CallMethodWithProgress(context.Background(), nil, "work", parameters.NewParametersByName(nil), func(value json.RawMessage) {
+		values = append(values, string(value))
+	})
+	if resErr != nil || string(*res) != `"done"` {
+		t.Fatalf("call = %v, %v, want done", res, resErr)
+	}
+
+	want := []string{"25", "50", "75", "100"}
+	if len(values) != len(want) {
+		t.Fatalf("progress = %v, want %v", values, want)
+	}
+	for i := range want {
+		if values[i] != want[i] {
+			t.Fatalf("progress = %v, want %v", values, want)
+		}
+	}
+
+	client.progressMutex.RLock()
+	defer client.progressMutex.RUnlock()
+	if len(client.progressFuncs) != 0 {
+		t.Errorf("%d progress callbacks left after the call", len(client.progressFuncs))
+	}
+}
+
+func TestProgressReporterWithoutToken(t *testing.T) {
+	if err := ProgressReporter(context.Background())(50); err != nil {
+		t.Errorf("report = %v, want nil when the caller did not ask for progress", err)
+	}
+}
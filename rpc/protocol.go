@@ -0,0 +1,40 @@
+package rpc
+
+import (
+	"encoding/json"
+
+	UUID "github.com/nu7hatch/gouuid"
+
+	"github.com/bob620/baka-rpc-go/parameters"
+	"github.com/bob620/baka-rpc-go/request"
+)
+
+// sendNotification sends a by-name notification synchronously, keeping it ordered with other messages sent by the caller
+func (rpc *BakaRpc) sendNotification(uuid *UUID.UUID, methodName string, values map[string]interface{}) error {
+	params := make([]parameters.Param, 0, len(values))
+	for name, value := range values {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		params = append(params, &parameters.GenericParam{Name: name, Default: data})
+	}
+
+	message, err := json.Marshal(request.NewNotification(methodName, parameters.NewParametersByName(params)))
+	if err != nil {
+		return err
+	}
+
+	rpc.sendMessage(message, uuid)
+	return nil
+}
+
+// readParam decodes the named param of a notification, returning false if it is missing or invalid
+func readParam(req *request.Request, name string, value interface{}) bool {
+	param := req.GetParams().Get(name)
+	if param == nil {
+		return false
+	}
+
+	return json.Unmarshal(param.GetData(), value) == nil
+}
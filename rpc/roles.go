@@ -0,0 +1,66 @@
+package rpc
+
+import (
+	UUID "github.com/nu7hatch/gouuid"
+)
+
+type ChannelOption func(config *channelConfig)
+
+type channelConfig struct {
+	roles     []string
+	principal *Principal
+	version   string
+}
+
+// ChannelRoles tags the channel with roles, methods registered with MethodRoles are only visible to matching channels
+func ChannelRoles(roles ...string) ChannelOption {
+	return func(config *channelConfig) {
+		config.roles = append(config.roles, roles...)
+	}
+}
+
+// MethodRoles hides the method from every channel without at least one of the roles, they get Method not found instead.
+// The roles of an authenticated channel's principal count as the channel's roles.
+func MethodRoles(roles ...string) MethodOption {
+	return func(method *method) {
+		method.roles = append(method.roles, roles...)
+	}
+}
+
+// SetChannelRoles replaces the roles of an attached channel
+func (rpc *BakaRpc) SetChannelRoles(uuid *UUID.UUID, roles ...string) {
+	rpc.chansMutex.Lock()
+	defer rpc.chansMutex.Unlock()
+
+	if rpc.chansOut[uuid] != nil {
+		rpc.chansRoles[uuid] = append([]string(nil), roles...)
+	}
+}
+
+func (rpc *BakaRpc) GetChannelRoles(uuid *UUID.UUID) []string {
+	rpc.chansMutex.RLock()
+	defer rpc.chansMutex.RUnlock()
+
+	return append([]string(nil), rpc.chansRoles[uuid]...)
+}
+
+func (rpc *BakaRpc) canSee(uuid *UUID.UUID, method *method) bool {
+	if len(method.roles) == 0 {
+		return true
+	}
+
+	channelRoles := rpc.GetChannelRoles(uuid)
+	if principal := rpc.GetChannelPrincipal(uuid); principal != nil {
+		channelRoles = append(channelRoles, principal.Roles...)
+	}
+
+	for _, channelRole := range channelRoles {
+		for _, role := range method.roles {
+			if channelRole == role {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,150 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/bob620/baka-rpc-go/parameters"
+)
+
+// Middleware wraps the handler of a method, it must call handler to continue handling the call
+type Middleware func(ctx context.Context, methodName string, params map[string]parameters.Param, handler ContextMethodFunc) (json.RawMessage, error)
+
+// Router registers methods under a namespace prefix, wrapping them in its middleware and every parent's. Packages can
+// take a *Router to register their methods without knowing where they are mounted.
+type Router struct {
+	rpc        *BakaRpc
+	prefix     string
+	middleware []Middleware
+	options    []MethodOption
+}
+
+// MethodMiddleware wraps the method's handler in middleware, the first middleware given is the outermost
+func MethodMiddleware(middleware ...Middleware) MethodOption {
+	return func(method *method) {
+		method.middleware = append(method.middleware, middleware...)
+	}
+}
+
+func chainMiddleware(middleware []Middleware, methodName string, final ContextMethodFunc) ContextMethodFunc {
+	handler := final
+	for i := len(middleware) - 1; i >= 0; i-- {
+		wrapper := middleware[i]
+		next := handler
+		handler = func(ctx context.Context, params map[string]parameters.Param) (json.RawMessage, error) {
+			return wrapper(ctx, methodName, params, next)
+		}
+	}
+
+	return handler
+}
+
+// Router returns a router registering methods without a prefix
+func (rpc *BakaRpc) Router() *Router {
+	return &Router{rpc: rpc}
+}
+
+// Group returns a router registering methods under the router's prefix followed by prefix, such as "user.", wrapped in
+// middleware inside the router's own
+func (router *Router) Group(prefix string, middleware ...Middleware) *Router {
+	return &Router{
+		rpc:        router.rpc,
+		prefix:     router.prefix + prefix,
+		middleware: append(append([]Middleware(nil), router.middleware...), middleware...),
+		options:    append([]MethodOption(nil), router.options...),
+	}
+}
+
+// Mount calls register with a group under prefix, for registering a namespace from a separate package
+func (router *Router) Mount(prefix string, register func(router *Router), middleware ...Middleware) *Router {
+	group := router.Group(prefix, middleware...)
+	register(group)
+
+	return group
+}
+
+// Use appends middleware for the methods registered through the router from now on
+func (router *Router) Use(middleware ...Middleware) {
+	router.middleware = append(router.middleware, middleware...)
+}
+
+// With appends options given to every method registered through the router from now on, such as MethodRoles
+func (router *Router) With(options ...MethodOption) {
+	router.options = append(router.options, options...)
+}
+
+func (router *Router) Prefix() string {
+	return router.prefix
+}
+
+func (router *Router) methodOptions(options []MethodOption) []MethodOption {
+	// The router's middleware wraps any given per method
+	routerOptions := append([]MethodOption(nil), router.options...)
+	if len(router.middleware) > 0 {
+		routerOptions = append(routerOptions, MethodMiddleware(router.middleware...))
+	}
+
+	return append(routerOptions, options...)
+}
+
+func (router *Router) RegisterMethod(methodName string, methodParams []parameters.Param, methodFunc MethodFunc, options ...MethodOption) {
+	router.rpc.RegisterMethod(router.prefix+methodName, methodParams, methodFunc, router.methodOptions(options)...)
+}
+
+func (router *Router) RegisterContextMethod(methodName string, methodParams []parameters.Param, methodFunc ContextMethodFunc, options ...MethodOption) {
+	router.rpc.RegisterContextMethod(router.prefix+methodName, methodParams, methodFunc, router.methodOptions(options)...)
+}
+
+func (router *Router) RegisterSubscription(name string, methodParams []parameters.Param, fn SubscriptionFunc, options ...MethodOption) {
+	router.rpc.RegisterSubscription(router.prefix+name, methodParams, fn, router.methodOptions(options)...)
+}
+
+func (router *Router) RegisterStreamMethod(name string, methodParams []parameters.Param, fn StreamMethodFunc, options ...MethodOption) {
+	router.rpc.RegisterStreamMethod(router.prefix+name, methodParams, fn, router.methodOptions(options)...)
+}
+
+// DeregisterMethod deregisters the method registered through the router as methodName
+func (router *Router) DeregisterMethod(methodName string) {
+	router.rpc.DeregisterMethod(router.prefix + methodName)
+}
+
+// Methods returns the full names of the methods under the router's prefix
+func (router *Router) Methods() []string {
+	return router.rpc.NamespaceMethods(router.prefix)
+}
+
+// Deregister deregisters every method under the router's prefix
+func (router *Router) Deregister() {
+	router.rpc.DeregisterNamespace(router.prefix)
+}
+
+// NamespaceMethods returns the sorted names of the methods starting with prefix
+func (rpc *BakaRpc) NamespaceMethods(prefix string) []string {
+	rpc.methodsMutex.RLock()
+	defer rpc.methodsMutex.RUnlock()
+
+	var names []string
+	for name := range rpc.methods {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// DeregisterNamespace deregisters every method starting with prefix
+func (rpc *BakaRpc) DeregisterNamespace(prefix string) {
+	rpc.methodsMutex.Lock()
+	defer rpc.methodsMutex.Unlock()
+
+	for name := range rpc.methods {
+		if strings.HasPrefix(name, prefix) {
+			delete(rpc.methods, name)
+			rpc.indexVersion(name, false)
+		}
+	}
+}
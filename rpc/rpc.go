@@ -2,35 +2,108 @@ package rpc
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"io"
-	"strconv"
 	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 	UUID "github.com/nu7hatch/gouuid"
 
 	"github.com/bob620/baka-rpc-go/errors"
+	"github.com/bob620/baka-rpc-go/openrpc"
 	"github.com/bob620/baka-rpc-go/parameters"
 	"github.com/bob620/baka-rpc-go/request"
 	"github.com/bob620/baka-rpc-go/response"
 )
 
 type MethodFunc func(params map[string]parameters.Param) (returnMessage json.RawMessage, err error)
+type ContextMethodFunc func(ctx context.Context, params map[string]parameters.Param) (returnMessage json.RawMessage, err error)
 
 type BakaRpc struct {
 	chansIn          map[*UUID.UUID]<-chan []byte
 	chansOut         map[*UUID.UUID]chan<- []byte
+	chansClosed      map[*UUID.UUID]chan struct{}
+	chansRoles       map[*UUID.UUID][]string
+	chansPrincipal   map[*UUID.UUID]*Principal
+	chansVersion     map[*UUID.UUID]string
+	chansNegotiation map[*UUID.UUID]*Negotiation
+	chansMutex       sync.RWMutex
 	methods          map[string]*method
+	methodsMutex     sync.RWMutex
 	callbackChans    map[string]*chan response.Response
 	callbackMutex    sync.RWMutex
 	disconnectHandle func(uuid *UUID.UUID)
+
+	// Guarded by methodsMutex
+	versions          map[string][]string
+	strictParams      bool
+	coercionHandle    func(ctx context.Context, coercion Coercion)
+	deprecationHandle func(ctx context.Context, deprecation Deprecation)
+	fallback          FallbackFunc
+
+	callInterceptors   []CallInterceptor
+	notifyInterceptors []NotifyInterceptor
+	interceptorsMutex  sync.RWMutex
+
+	running      map[runningKey]context.CancelFunc
+	runningMutex sync.Mutex
+
+	progressFuncs map[runningKey]ProgressFunc
+	progressMutex sync.RWMutex
+
+	subscriptions      map[runningKey]context.CancelFunc
+	subscriptionsMutex sync.Mutex
+	subscribers        map[string]*Subscription
+	subscribersMutex   sync.RWMutex
+
+	streamWriters map[runningKey]*StreamWriter
+	streamCancels map[runningKey]context.CancelFunc
+	streamReaders map[string]*StreamReader
+	streamsMutex  sync.Mutex
+
+	groups      map[string]map[*UUID.UUID]struct{}
+	groupsMutex sync.RWMutex
+
+	authenticator Authenticator
+	authDeadline  time.Duration
+	authMutex     sync.RWMutex
+
+	healthChecks map[string]HealthCheck
+	healthMutex  sync.RWMutex
+
+	negotiation      negotiationConfig
+	negotiationMutex sync.RWMutex
+
+	limits      Limits
+	queue       chan func()
+	slots       chan struct{}
+	inFlight    map[*UUID.UUID]int
+	rejected    uint64
+	limitsMutex sync.RWMutex
 }
 
 type method struct {
 	name       string
 	params     []parameters.Param
-	methodFunc *MethodFunc
+	methodFunc ContextMethodFunc
+	timeout    time.Duration
+	semaphore  chan struct{}
+	roles      []string
+	public     bool
+	strict     bool
+	coerce     bool
+	aliases    map[string][]string
+	deprecated string
+	middleware []Middleware
+
+	paramValidators map[string][]ParamValidator
+	validators      []ParamsValidator
+
+	description string
+	result      *openrpc.ContentDescriptor
+	kind        string
 }
 
 func MakeReaderChan(r io.Reader) <-chan []byte {
@@ -39,7 +112,8 @@ func MakeReaderChan(r io.Reader) <-chan []byte {
 		defer close(data)
 		scan := bufio.NewScanner(r)
 		for scan.Scan() {
-			data <- scan.Bytes()
+			// Scan reuses its buffer, so copy before handing the line off
+			data <- append([]byte(nil), scan.Bytes()...)
 		}
 	}()
 	return data
@@ -101,10 +175,26 @@ func MakeSocketWriterChan(conn *websocket.Conn) (writerChan chan []byte) {
 
 func CreateBakaRpc(chanIn <-chan []byte, chanOut chan<- []byte) *BakaRpc {
 	rpc := &BakaRpc{
-		chansIn:       map[*UUID.UUID]<-chan []byte{},
-		chansOut:      map[*UUID.UUID]chan<- []byte{},
-		methods:       map[string]*method{},
-		callbackChans: map[string]*chan response.Response{},
+		chansIn:          map[*UUID.UUID]<-chan []byte{},
+		chansOut:         map[*UUID.UUID]chan<- []byte{},
+		chansClosed:      map[*UUID.UUID]chan struct{}{},
+		chansRoles:       map[*UUID.UUID][]string{},
+		chansPrincipal:   map[*UUID.UUID]*Principal{},
+		chansVersion:     map[*UUID.UUID]string{},
+		chansNegotiation: map[*UUID.UUID]*Negotiation{},
+		methods:          map[string]*method{},
+		versions:         map[string][]string{},
+		callbackChans:    map[string]*chan response.Response{},
+		inFlight:         map[*UUID.UUID]int{},
+		running:          map[runningKey]context.CancelFunc{},
+		progressFuncs:    map[runningKey]ProgressFunc{},
+		subscriptions:    map[runningKey]context.CancelFunc{},
+		subscribers:      map[string]*Subscription{},
+		streamWriters:    map[runningKey]*StreamWriter{},
+		streamCancels:    map[runningKey]context.CancelFunc{},
+		streamReaders:    map[string]*StreamReader{},
+		groups:           map[string]map[*UUID.UUID]struct{}{},
+		healthChecks:     map[string]HealthCheck{},
 	}
 
 	if chanIn != nil && chanOut != nil {
@@ -118,101 +208,146 @@ func (rpc *BakaRpc) HandleDisconnect(handle func(uuid *UUID.UUID)) {
 	rpc.disconnectHandle = handle
 }
 
-func (rpc *BakaRpc) AddChannels(chanIn <-chan []byte, chanOut chan<- []byte) (uuid *UUID.UUID) {
-	uuid, _ = UUID.NewV4()
-
-	rpc.chansIn[uuid] = chanIn
-	rpc.chansOut[uuid] = chanOut
+func (rpc *BakaRpc) AddChannels(chanIn <-chan []byte, chanOut chan<- []byte, options ...ChannelOption) (uuid *UUID.UUID) {
+	uuid = rpc.attachChannels(chanIn, chanOut, options)
 
 	go rpc.start(uuid)
 
 	return
 }
 
-func (rpc *BakaRpc) UseChannels(chanIn <-chan []byte, chanOut chan<- []byte) {
-	uuid, _ := UUID.NewV4()
+func (rpc *BakaRpc) UseChannels(chanIn <-chan []byte, chanOut chan<- []byte, options ...ChannelOption) {
+	uuid := rpc.attachChannels(chanIn, chanOut, options)
+
+	rpc.start(uuid)
+	rpc.RemoveChannels(uuid)
+
+	return
+}
 
+func (rpc *BakaRpc) attachChannels(chanIn <-chan []byte, chanOut chan<- []byte, options []ChannelOption) (uuid *UUID.UUID) {
+	uuid, _ = UUID.NewV4()
+
+	config := channelConfig{}
+	for _, option := range options {
+		option(&config)
+	}
+
+	rpc.chansMutex.Lock()
 	rpc.chansIn[uuid] = chanIn
 	rpc.chansOut[uuid] = chanOut
+	rpc.chansClosed[uuid] = make(chan struct{})
+	rpc.chansRoles[uuid] = config.roles
+	rpc.chansPrincipal[uuid] = config.principal
+	rpc.chansVersion[uuid] = config.version
+	rpc.chansMutex.Unlock()
 
-	rpc.start(uuid)
-	rpc.RemoveChannels(uuid)
+	rpc.startAuthDeadline(uuid)
+	rpc.startNegotiation(uuid)
 
 	return
 }
 
+// RemoveChannels closes and removes the channel, or every channel when uuid is nil, without calling the disconnect handle
 func (rpc *BakaRpc) RemoveChannels(uuid *UUID.UUID) {
-	if uuid != nil {
-		delete(rpc.chansIn, uuid)
-		delete(rpc.chansOut, uuid)
-	} else {
-		rpc.chansIn = map[*UUID.UUID]<-chan []byte{}
-		rpc.chansOut = map[*UUID.UUID]chan<- []byte{}
+	uuids := []*UUID.UUID{uuid}
+	if uuid == nil {
+		uuids = rpc.Channels()
+	}
+
+	for _, uuid := range uuids {
+		rpc.closeChannel(uuid)
+		rpc.deleteChannel(uuid)
 	}
 }
 
-func (rpc *BakaRpc) handleRequest(req request.Request) (message json.RawMessage, errRpc *errors.RPCError) {
-	method := rpc.methods[req.GetMethod()]
-	if method == nil {
-		return nil, errors.NewMethodNotFound()
+// closeChannel cleans up everything tied to the channel, returning false if it was already closed
+func (rpc *BakaRpc) closeChannel(uuid *UUID.UUID) bool {
+	// Only the first close of a channel, e.g. by the authentication deadline and then by its reader, cleans up
+	rpc.chansMutex.Lock()
+	closed := rpc.chansClosed[uuid]
+	delete(rpc.chansClosed, uuid)
+	rpc.chansMutex.Unlock()
+
+	if closed == nil {
+		return false
 	}
+	// Closing wakes up any call still waiting on a response from the channel
+	close(closed)
 
-	numRequiredParams := 0
-	reqParams := req.GetParams()
-	sanitizedParams := map[string]parameters.Param{}
-	// Set default values for everything, assuming we will get all required params
-	for _, param := range method.params {
-		name := param.GetName()
-
-		// Clone and add if required
-		sanitizedParams[name], _ = param.Clone(nil)
-		if param.IsRequired() {
-			numRequiredParams++
-		}
+	rpc.cancelChannelRequests(uuid)
+	rpc.endChannelSubscriptions(uuid)
+	rpc.endChannelStreams(uuid)
+	rpc.leaveAllGroups(uuid)
 
-		// Make sure we can assume we get all required params
-		if reqParams.Length() < numRequiredParams {
-			return nil, errors.NewInvalidParams()
-		}
+	return true
+}
+
+func (rpc *BakaRpc) deleteChannel(uuid *UUID.UUID) {
+	rpc.chansMutex.Lock()
+	defer rpc.chansMutex.Unlock()
+
+	delete(rpc.chansIn, uuid)
+	delete(rpc.chansOut, uuid)
+	delete(rpc.chansClosed, uuid)
+	delete(rpc.chansRoles, uuid)
+	delete(rpc.chansPrincipal, uuid)
+	delete(rpc.chansVersion, uuid)
+	delete(rpc.chansNegotiation, uuid)
+}
+
+func (rpc *BakaRpc) defaultChannel() *UUID.UUID {
+	rpc.chansMutex.RLock()
+	defer rpc.chansMutex.RUnlock()
+
+	for uuid, _ := range rpc.chansOut {
+		return uuid
+	}
+
+	return nil
+}
+
+func (rpc *BakaRpc) handleRequest(ctx context.Context, req request.Request) (message json.RawMessage, errRpc *errors.RPCError) {
+	rpc.methodsMutex.RLock()
+	method := rpc.resolveMethod(ChannelFromContext(ctx), req.GetMethod())
+	strict := rpc.strictParams
+	coercionHandle := rpc.coercionHandle
+	deprecationHandle := rpc.deprecationHandle
+	fallback := rpc.fallback
+	rpc.methodsMutex.RUnlock()
+	// Nothing but authenticating is dispatched until the channel has a valid principal
+	if (method == nil || !method.public) && rpc.requiresAuthentication() && PrincipalFromContext(ctx) == nil {
+		return nil, errors.NewUnauthorized()
 	}
 
-	// Iterate through the request params to change from default values
-	switch reqParams.GetType() {
-	case parameters.ByName:
-		for _, param := range sanitizedParams {
-			name := param.GetName()
-			reqParam := reqParams.Get(name)
+	if method == nil && fallback != nil {
+		return fallbackMethod(req.GetMethod(), req.GetParams(), fallback).call(ctx, nil)
+	}
 
-			if reqParam != nil {
-				_ = sanitizedParams[name].SetData(reqParam.GetData())
-			} else {
-				if param.IsRequired() {
-					return nil, errors.NewInvalidParams()
-				}
-			}
-		}
-		break
-	case parameters.ByPosition:
-		for index, param := range method.params {
-			reqParam := reqParams.Get(strconv.Itoa(index))
+	if method == nil || !rpc.canSee(ChannelFromContext(ctx), method) {
+		return nil, errors.NewMethodNotFound()
+	}
 
-			if reqParam != nil {
-				_ = sanitizedParams[param.GetName()].SetData(reqParam.GetData())
-			} else {
-				if param.IsRequired() {
-					return nil, errors.NewInvalidParams()
-				}
-			}
+	if method.deprecated != "" && deprecationHandle != nil {
+		deprecationHandle(ctx, Deprecation{ChannelFromContext(ctx), req.GetMethod(), method.name, method.deprecated})
+	}
+
+	sanitizedParams, coercions, errRpc := bindParams(method, req.GetParams(), strict || method.strict)
+	if errRpc != nil {
+		return nil, errRpc
+	}
+
+	if coercionHandle != nil {
+		for _, coercion := range coercions {
+			coercionHandle(ctx, coercion)
 		}
-		break
 	}
 
-	data, err := (*method.methodFunc)(sanitizedParams)
-	if err != nil {
-		return nil, errors.NewGenericError(err.Error())
+	if errRpc = method.validate(ctx, sanitizedParams); errRpc != nil {
+		return nil, errRpc
 	}
 
-	return data, nil
+	return method.call(ctx, sanitizedParams)
 }
 
 func (rpc *BakaRpc) handleResponse(res response.Response) {
@@ -240,32 +375,57 @@ func (rpc *BakaRpc) CallMethodWithNone(channelUuid *UUID.UUID, methodName string
 }
 
 func (rpc *BakaRpc) CallMethod(channelUuid *UUID.UUID, methodName string, params *parameters.Parameters) (res *json.RawMessage, resErr *errors.RPCError) {
+	return rpc.CallMethodContext(context.Background(), channelUuid, methodName, params)
+}
+
+// CallMethodContext calls the method and waits for the response, cancelling ctx sends a $/cancelRequest to the remote
+func (rpc *BakaRpc) CallMethodContext(ctx context.Context, channelUuid *UUID.UUID, methodName string, params *parameters.Parameters) (res *json.RawMessage, resErr *errors.RPCError) {
+	if channelUuid == nil {
+		channelUuid = rpc.defaultChannel()
+	}
+
+	rpc.interceptorsMutex.RLock()
+	interceptors := rpc.callInterceptors
+	rpc.interceptorsMutex.RUnlock()
+
+	return chainCallInterceptors(interceptors, rpc.callMethod)(ctx, channelUuid, methodName, params)
+}
+
+func (rpc *BakaRpc) callMethod(ctx context.Context, channelUuid *UUID.UUID, methodName string, params *parameters.Parameters) (res *json.RawMessage, resErr *errors.RPCError) {
+	rpc.chansMutex.RLock()
+	closed := rpc.chansClosed[channelUuid]
+	rpc.chansMutex.RUnlock()
+
+	if closed == nil {
+		return nil, errors.NewGenericError("Channel Closed")
+	}
+
 	method := request.NewRequest(methodName, "", params)
+	defer rpc.trackProgress(ctx, channelUuid, method)()
+	rpc.trackSubscription(ctx, channelUuid, method)
+	rpc.trackStream(ctx, channelUuid, method)
 
 	data, err := json.Marshal(method)
 	if err != nil {
 		return nil, errors.NewParseError()
 	}
 
-	callback := make(chan response.Response)
+	// Buffered so a late response never blocks handleResponse after we stopped waiting
+	callback := make(chan response.Response, 1)
 	rpc.callbackMutex.Lock()
 	rpc.callbackChans[method.GetId()] = &callback
 	rpc.callbackMutex.Unlock()
 
-	if channelUuid == nil {
-		for uuid, _ := range rpc.chansOut {
-			channelUuid = uuid
-			break
-		}
-	}
-
-	if channelUuid != nil {
-		go rpc.sendMessage(data, channelUuid)
-		remoteRes := <-callback
+	defer func() {
 		rpc.callbackMutex.Lock()
 		delete(rpc.callbackChans, method.GetId())
 		rpc.callbackMutex.Unlock()
+	}()
+
+	go rpc.sendMessage(data, channelUuid)
 
+	select {
+	case remoteRes := <-callback:
 		if remoteRes.GetType() == response.ErrorType {
 			resErr = remoteRes.GetError()
 			return nil, resErr
@@ -273,8 +433,12 @@ func (rpc *BakaRpc) CallMethod(channelUuid *UUID.UUID, methodName string, params
 			res = remoteRes.GetResult()
 			return res, nil
 		}
+	case <-ctx.Done():
+		rpc.sendCancelRequest(channelUuid, method.GetId())
+		return nil, errors.NewRequestCancelled()
+	case <-closed:
+		return nil, errors.NewGenericError("Channel Closed")
 	}
-	return nil, errors.NewGenericError("Channel Closed")
 }
 
 func (rpc *BakaRpc) NotifyMethodByName(channelUuid *UUID.UUID, methodName string, params ...parameters.Param) {
@@ -291,34 +455,51 @@ func (rpc *BakaRpc) NotifyMethodWithNone(channelUuid *UUID.UUID, methodName stri
 
 func (rpc *BakaRpc) NotifyMethod(channelUuid *UUID.UUID, methodName string, params *parameters.Parameters) {
 	if channelUuid == nil {
-		for uuid, _ := range rpc.chansOut {
-			channelUuid = uuid
-			break
-		}
+		channelUuid = rpc.defaultChannel()
 	}
 
+	rpc.interceptorsMutex.RLock()
+	interceptors := rpc.notifyInterceptors
+	rpc.interceptorsMutex.RUnlock()
+
+	chainNotifyInterceptors(interceptors, rpc.notifyMethod)(context.Background(), channelUuid, methodName, params)
+}
+
+func (rpc *BakaRpc) notifyMethod(_ context.Context, channelUuid *UUID.UUID, methodName string, params *parameters.Parameters) {
 	data, err := json.Marshal(request.NewNotification(methodName, params))
 	if err == nil && channelUuid != nil {
 		go rpc.sendMessage(data, channelUuid)
 	}
 }
 
+// protocolNotifications are handled on the channel's reader, in order, instead of being dispatched as methods
+var protocolNotifications = map[string]func(rpc *BakaRpc, uuid *UUID.UUID, req *request.Request){
+	CancelRequestMethod: (*BakaRpc).handleCancelRequest,
+	ProgressMethod:      (*BakaRpc).handleProgress,
+	SubscriptionMethod:  (*BakaRpc).handleSubscriptionMessage,
+	UnsubscribeMethod:   (*BakaRpc).handleUnsubscribe,
+	StreamChunkMethod:   (*BakaRpc).handleStreamChunk,
+	StreamAckMethod:     (*BakaRpc).handleStreamAck,
+	StreamEndMethod:     (*BakaRpc).handleStreamEnd,
+	StreamCancelMethod:  (*BakaRpc).handleStreamCancel,
+}
+
 func (rpc *BakaRpc) start(uuid *UUID.UUID) {
-	res := response.Response{}
-	req := request.Request{}
+	rpc.chansMutex.RLock()
+	chanIn := rpc.chansIn[uuid]
+	rpc.chansMutex.RUnlock()
 
-	for rpc.chansIn[uuid] != nil {
-		message := <-rpc.chansIn[uuid]
+	for chanIn != nil {
+		message := <-chanIn
 
 		if message == nil {
-			rpc.sendMessage(nil, uuid)
-			rpc.RemoveChannels(uuid)
-			if rpc.disconnectHandle != nil {
-				rpc.disconnectHandle(uuid)
-			}
+			rpc.disconnect(uuid)
 			break
 		}
 
+		res := response.Response{}
+		req := request.Request{}
+
 		err := json.Unmarshal(message, &req)
 		if err != nil {
 			err = json.Unmarshal(message, &res)
@@ -332,17 +513,10 @@ func (rpc *BakaRpc) start(uuid *UUID.UUID) {
 			if req.GetRpcVersion() != "2.0" {
 				data, _ := json.Marshal(response.NewErrorResponse(req.GetId(), errors.NewInvalidRequest()))
 				go rpc.sendMessage(data, uuid)
+			} else if handle := protocolNotifications[req.GetMethod()]; handle != nil {
+				handle(rpc, uuid, &req)
 			} else {
-				go func() {
-					message, err := rpc.handleRequest(req)
-					if err != nil {
-						data, _ := json.Marshal(response.NewErrorResponse(req.GetId(), err))
-						rpc.sendMessage(data, uuid)
-					} else {
-						data, _ := json.Marshal(response.NewSuccessResponse(req.GetId(), message))
-						rpc.sendMessage(data, uuid)
-					}
-				}()
+				rpc.dispatch(uuid, &req)
 			}
 		}
 
@@ -354,21 +528,84 @@ func (rpc *BakaRpc) start(uuid *UUID.UUID) {
 				go rpc.handleResponse(res)
 			}
 		}
+
+		rpc.chansMutex.RLock()
+		chanIn = rpc.chansIn[uuid]
+		rpc.chansMutex.RUnlock()
+	}
+}
+
+// disconnect cleans up everything tied to the channel, then closes and removes it
+func (rpc *BakaRpc) disconnect(uuid *UUID.UUID) {
+	if !rpc.closeChannel(uuid) {
+		return
+	}
+
+	rpc.sendMessage(nil, uuid)
+	rpc.deleteChannel(uuid)
+	if rpc.disconnectHandle != nil {
+		rpc.disconnectHandle(uuid)
+	}
+}
+
+func (rpc *BakaRpc) processRequest(ctx context.Context, uuid *UUID.UUID, req *request.Request) {
+	ctx = context.WithValue(ctx, channelKey{}, uuid)
+	ctx = context.WithValue(ctx, requestIdKey{}, req.GetId())
+	ctx = context.WithValue(ctx, principalKey{}, rpc.GetChannelPrincipal(uuid))
+	message, err := rpc.handleRequest(rpc.withProgressReporter(ctx, uuid, req), *req)
+	if err != nil {
+		data, _ := json.Marshal(response.NewErrorResponse(req.GetId(), err))
+		rpc.sendMessage(data, uuid)
+	} else {
+		data, _ := json.Marshal(response.NewSuccessResponse(req.GetId(), message))
+		rpc.sendMessage(data, uuid)
 	}
 }
 
 func (rpc *BakaRpc) sendMessage(message json.RawMessage, uuid *UUID.UUID) {
-	rpc.chansOut[uuid] <- message
+	rpc.chansMutex.RLock()
+	chanOut := rpc.chansOut[uuid]
+	rpc.chansMutex.RUnlock()
+
+	if chanOut != nil {
+		chanOut <- message
+	}
+}
+
+func (rpc *BakaRpc) RegisterMethod(methodName string, methodParams []parameters.Param, methodFunc MethodFunc, options ...MethodOption) {
+	rpc.RegisterContextMethod(methodName, methodParams, func(_ context.Context, params map[string]parameters.Param) (json.RawMessage, error) {
+		return methodFunc(params)
+	}, options...)
 }
 
-func (rpc *BakaRpc) RegisterMethod(methodName string, methodParams []parameters.Param, methodFunc MethodFunc) {
-	rpc.methods[methodName] = &method{
+// RegisterContextMethod panics if a RestParam is not the method's last param
+func (rpc *BakaRpc) RegisterContextMethod(methodName string, methodParams []parameters.Param, methodFunc ContextMethodFunc, options ...MethodOption) {
+	for index, param := range methodParams {
+		if _, ok := param.(*parameters.RestParam); ok && index != len(methodParams)-1 {
+			panic("method " + methodName + " declares a RestParam that is not its last param")
+		}
+	}
+
+	newMethod := &method{
 		name:       methodName,
 		params:     methodParams,
-		methodFunc: &methodFunc,
+		methodFunc: methodFunc,
 	}
+
+	for _, option := range options {
+		option(newMethod)
+	}
+	newMethod.methodFunc = chainMiddleware(newMethod.middleware, methodName, methodFunc)
+
+	rpc.methodsMutex.Lock()
+	rpc.methods[methodName] = newMethod
+	rpc.indexVersion(methodName, true)
+	rpc.methodsMutex.Unlock()
 }
 
 func (rpc *BakaRpc) DeregisterMethod(methodName string) {
+	rpc.methodsMutex.Lock()
 	delete(rpc.methods, methodName)
+	rpc.indexVersion(methodName, false)
+	rpc.methodsMutex.Unlock()
 }
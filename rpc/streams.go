@@ -0,0 +1,435 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	errs "errors"
+	"io"
+	"sync"
+
+	UUID "github.com/nu7hatch/gouuid"
+
+	"github.com/bob620/baka-rpc-go/errors"
+	"github.com/bob620/baka-rpc-go/openrpc"
+	"github.com/bob620/baka-rpc-go/parameters"
+	"github.com/bob620/baka-rpc-go/request"
+)
+
+const (
+	// StreamChunkMethod carries one ordered frame of a streamed result
+	StreamChunkMethod = "$/stream.chunk"
+	// StreamAckMethod is sent by the reader for every frame it consumed, granting the writer another frame
+	StreamAckMethod = "$/stream.ack"
+	// StreamEndMethod ends a stream, optionally with the error the handler returned
+	StreamEndMethod = "$/stream.end"
+	// StreamCancelMethod is sent by the reader to stop the writer
+	StreamCancelMethod = "$/stream.cancel"
+
+	// StreamChunkSize keeps every frame well below the line limit of MakeReaderChan
+	StreamChunkSize = 16 * 1024
+	// StreamWindow is how many frames may be unacknowledged before the writer blocks
+	StreamWindow = 16
+)
+
+// StreamMethodFunc writes its result to stream, returning ends the stream
+type StreamMethodFunc func(ctx context.Context, params map[string]parameters.Param, stream *StreamWriter) error
+
+// StreamWriter sends a streamed result in ordered frames, blocking while the reader is StreamWindow frames behind
+type StreamWriter struct {
+	rpc    *BakaRpc
+	ctx    context.Context
+	uuid   *UUID.UUID
+	id     string
+	seq    uint64
+	credit chan struct{}
+}
+
+// StreamReader reads a streamed result made with CallStream
+type StreamReader struct {
+	rpc  *BakaRpc
+	uuid *UUID.UUID
+	id   string
+
+	chunks    chan []byte
+	current   []byte
+	err       *errors.RPCError
+	ended     chan struct{}
+	closed    chan struct{}
+	closeOnce sync.Once
+	// Guards pushing to chunks against end closing it from another goroutine
+	chunksMutex sync.Mutex
+}
+
+type streamKey struct{}
+
+type streamResult struct {
+	Stream string `json:"stream"`
+}
+
+// RegisterStreamMethod registers a method that answers with a stream id and writes its result as $/stream.chunk frames
+func (rpc *BakaRpc) RegisterStreamMethod(name string, methodParams []parameters.Param, fn StreamMethodFunc, options ...MethodOption) {
+	rpc.RegisterContextMethod(name, methodParams, func(ctx context.Context, params map[string]parameters.Param) (json.RawMessage, error) {
+		uuid := ChannelFromContext(ctx)
+		id := requestIdFromContext(ctx)
+		if uuid == nil || id == "" {
+			return nil, errs.New("streams require a request")
+		}
+
+		// The writer outlives the request, so it only ends on cancel, disconnect or returning
+		streamCtx, cancel := context.WithCancel(context.Background())
+		streamCtx = context.WithValue(streamCtx, channelKey{}, uuid)
+		key := runningKey{uuid, id}
+
+		writer := &StreamWriter{
+			rpc:    rpc,
+			ctx:    streamCtx,
+			uuid:   uuid,
+			id:     id,
+			credit: make(chan struct{}, StreamWindow),
+		}
+		for i := 0; i < StreamWindow; i++ {
+			writer.credit <- struct{}{}
+		}
+
+		rpc.streamsMutex.Lock()
+		rpc.streamWriters[key] = writer
+		rpc.streamCancels[key] = cancel
+		rpc.streamsMutex.Unlock()
+
+		go func() {
+			err := fn(streamCtx, params, writer)
+
+			rpc.streamsMutex.Lock()
+			delete(rpc.streamWriters, key)
+			delete(rpc.streamCancels, key)
+			rpc.streamsMutex.Unlock()
+
+			// Nothing to tell the reader once it cancelled or disconnected
+			if streamCtx.Err() == nil {
+				end := map[string]interface{}{"stream": id}
+				if err != nil {
+					end["error"] = errors.NewGenericError(err.Error())
+				}
+				_ = rpc.sendNotification(uuid, StreamEndMethod, end)
+			}
+			cancel()
+		}()
+
+		return json.Marshal(streamResult{id})
+	}, append([]MethodOption{methodKind(openrpc.KindStream)}, options...)...)
+}
+
+// Write sends p as one or more frames, it errors once the reader cancelled or disconnected
+func (stream *StreamWriter) Write(p []byte) (n int, err error) {
+	for len(p) > 0 {
+		size := len(p)
+		if size > StreamChunkSize {
+			size = StreamChunkSize
+		}
+
+		select {
+		case <-stream.credit:
+		case <-stream.ctx.Done():
+			return n, stream.ctx.Err()
+		}
+
+		err = stream.rpc.sendNotification(stream.uuid, StreamChunkMethod, map[string]interface{}{
+			"stream": stream.id,
+			"seq":    stream.seq,
+			"data":   p[:size],
+		})
+		if err != nil {
+			return n, err
+		}
+
+		stream.seq++
+		n += size
+		p = p[size:]
+	}
+
+	return n, nil
+}
+
+// ReadFrom streams r until EOF, so io.Copy(stream, r) works
+func (stream *StreamWriter) ReadFrom(r io.Reader) (n int64, err error) {
+	buffer := make([]byte, StreamChunkSize)
+	for {
+		read, readErr := r.Read(buffer)
+		if read > 0 {
+			written, err := stream.Write(buffer[:read])
+			n += int64(written)
+			if err != nil {
+				return n, err
+			}
+		}
+
+		if readErr == io.EOF {
+			return n, nil
+		}
+		if readErr != nil {
+			return n, readErr
+		}
+	}
+}
+
+// Done is closed when the reader cancels or disconnects
+func (stream *StreamWriter) Done() <-chan struct{} {
+	return stream.ctx.Done()
+}
+
+// CallStream calls a stream method, the result is read from the returned reader until io.EOF
+func (rpc *BakaRpc) CallStream(ctx context.Context, channelUuid *UUID.UUID, methodName string, params *parameters.Parameters) (*StreamReader, *errors.RPCError) {
+	if channelUuid == nil {
+		channelUuid = rpc.defaultChannel()
+	}
+	if !rpc.peerSupports(channelUuid, ExtensionStreaming) {
+		return nil, errors.NewGenericError("Streaming not supported")
+	}
+
+	reader := &StreamReader{
+		rpc:    rpc,
+		chunks: make(chan []byte, StreamWindow),
+		ended:  make(chan struct{}),
+		closed: make(chan struct{}),
+	}
+
+	_, resErr := rpc.CallMethodContext(context.WithValue(ctx, streamKey{}, reader), channelUuid, methodName, params)
+	if resErr != nil {
+		reader.forget(reader.id)
+		return nil, resErr
+	}
+
+	if ctx.Done() != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				_ = reader.Close()
+			case <-reader.ended:
+			case <-reader.closed:
+			}
+		}()
+	}
+
+	return reader, nil
+}
+
+func (reader *StreamReader) Read(p []byte) (n int, err error) {
+	if len(reader.current) == 0 {
+		select {
+		case chunk, ok := <-reader.chunks:
+			if !ok {
+				if reader.err != nil {
+					return 0, reader.err
+				}
+				return 0, io.EOF
+			}
+			reader.current = chunk
+			reader.ack()
+		case <-reader.closed:
+			return 0, io.ErrClosedPipe
+		}
+	}
+
+	n = copy(p, reader.current)
+	reader.current = reader.current[n:]
+	return n, nil
+}
+
+// Close cancels the stream on the remote if it has not ended yet
+func (reader *StreamReader) Close() error {
+	reader.closeOnce.Do(func() {
+		close(reader.closed)
+		if reader.forget(reader.id) {
+			_ = reader.rpc.sendNotification(reader.uuid, StreamCancelMethod, map[string]interface{}{"stream": reader.id})
+		}
+	})
+
+	return nil
+}
+
+func (reader *StreamReader) ack() {
+	go reader.rpc.sendNotification(reader.uuid, StreamAckMethod, map[string]interface{}{"stream": reader.id})
+}
+
+// forget stops routing frames of the request id to the reader, returning false if that stream had already ended
+func (reader *StreamReader) forget(id string) bool {
+	reader.rpc.streamsMutex.Lock()
+	defer reader.rpc.streamsMutex.Unlock()
+
+	if reader.rpc.streamReaders[id] != reader {
+		return false
+	}
+	delete(reader.rpc.streamReaders, id)
+	return true
+}
+
+// end ends the stream of the request id, it may be called from any goroutine as a disconnect ends the stream while
+// the channel's reader may still push chunks
+func (reader *StreamReader) end(id string, err *errors.RPCError) {
+	reader.chunksMutex.Lock()
+	defer reader.chunksMutex.Unlock()
+
+	if !reader.forget(id) {
+		return
+	}
+
+	reader.err = err
+	close(reader.chunks)
+	close(reader.ended)
+}
+
+// push queues a chunk of the request id, returning false if the buffer is full. Chunks arriving after the stream ended,
+// or for an attempt an interceptor replaced, are dropped.
+func (reader *StreamReader) push(id string, data []byte) bool {
+	reader.chunksMutex.Lock()
+	defer reader.chunksMutex.Unlock()
+
+	select {
+	case <-reader.ended:
+		return true
+	default:
+	}
+	if reader.id != id {
+		return true
+	}
+
+	select {
+	case reader.chunks <- data:
+		return true
+	default:
+		return false
+	}
+}
+
+// trackStream registers the reader under the request id before the call is sent, so no early frame is lost. An
+// interceptor retrying the call tracks it again, replacing the previous attempt and cancelling its stream.
+func (rpc *BakaRpc) trackStream(ctx context.Context, uuid *UUID.UUID, req *request.Request) {
+	reader, ok := ctx.Value(streamKey{}).(*StreamReader)
+	if !ok {
+		return
+	}
+
+	reader.chunksMutex.Lock()
+	previousUuid, previousId := reader.uuid, reader.id
+
+	rpc.streamsMutex.Lock()
+	if previousId != "" && rpc.streamReaders[previousId] == reader {
+		delete(rpc.streamReaders, previousId)
+	}
+	reader.uuid = uuid
+	reader.id = req.GetId()
+	rpc.streamReaders[reader.id] = reader
+	rpc.streamsMutex.Unlock()
+
+	// Frames or the end of the previous attempt must not reach the caller
+	if previousId != "" {
+		reader.chunks = make(chan []byte, StreamWindow)
+		reader.ended = make(chan struct{})
+		reader.err = nil
+	}
+	reader.chunksMutex.Unlock()
+
+	if previousId != "" {
+		_ = rpc.sendNotification(previousUuid, StreamCancelMethod, map[string]interface{}{"stream": previousId})
+	}
+}
+
+func (rpc *BakaRpc) getStreamReader(uuid *UUID.UUID, req *request.Request) (*StreamReader, string) {
+	var id string
+	if !readParam(req, "stream", &id) {
+		return nil, ""
+	}
+
+	rpc.streamsMutex.Lock()
+	defer rpc.streamsMutex.Unlock()
+
+	reader := rpc.streamReaders[id]
+	if reader == nil || reader.uuid != uuid {
+		return nil, ""
+	}
+	return reader, id
+}
+
+func (rpc *BakaRpc) handleStreamChunk(uuid *UUID.UUID, req *request.Request) {
+	reader, id := rpc.getStreamReader(uuid, req)
+	if reader == nil {
+		return
+	}
+
+	var data []byte
+	if !readParam(req, "data", &data) {
+		reader.end(id, errors.NewGenericError("Invalid stream frame"))
+		return
+	}
+
+	// The writer never exceeds the window, a full buffer means the remote ignored flow control
+	if !reader.push(id, data) {
+		reader.end(id, errors.NewGenericError("Stream window exceeded"))
+		_ = rpc.sendNotification(uuid, StreamCancelMethod, map[string]interface{}{"stream": id})
+	}
+}
+
+func (rpc *BakaRpc) handleStreamEnd(uuid *UUID.UUID, req *request.Request) {
+	reader, id := rpc.getStreamReader(uuid, req)
+	if reader == nil {
+		return
+	}
+
+	var resErr *errors.RPCError
+	readParam(req, "error", &resErr)
+	reader.end(id, resErr)
+}
+
+func (rpc *BakaRpc) handleStreamAck(uuid *UUID.UUID, req *request.Request) {
+	var id string
+	if !readParam(req, "stream", &id) {
+		return
+	}
+
+	rpc.streamsMutex.Lock()
+	writer := rpc.streamWriters[runningKey{uuid, id}]
+	rpc.streamsMutex.Unlock()
+
+	if writer != nil {
+		select {
+		case writer.credit <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (rpc *BakaRpc) handleStreamCancel(uuid *UUID.UUID, req *request.Request) {
+	var id string
+	if !readParam(req, "stream", &id) {
+		return
+	}
+
+	rpc.streamsMutex.Lock()
+	cancel := rpc.streamCancels[runningKey{uuid, id}]
+	rpc.streamsMutex.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+}
+
+func (rpc *BakaRpc) endChannelStreams(uuid *UUID.UUID) {
+	readers := map[string]*StreamReader{}
+
+	rpc.streamsMutex.Lock()
+	for key, cancel := range rpc.streamCancels {
+		if key.uuid == uuid {
+			cancel()
+		}
+	}
+	for id, reader := range rpc.streamReaders {
+		if reader.uuid == uuid {
+			readers[id] = reader
+		}
+	}
+	rpc.streamsMutex.Unlock()
+
+	for id, reader := range readers {
+		reader.end(id, errors.NewGenericError("Channel Closed"))
+	}
+}
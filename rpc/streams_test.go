@@ -0,0 +1,220 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bob620/baka-rpc-go/errors"
+	"github.com/bob620/baka-rpc-go/parameters"
+)
+
+func readAll(t *testing.T, reader *StreamReader) ([]byte, error) {
+	t.Helper()
+
+	type result struct {
+		data []byte
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		data, err := io.ReadAll(reader)
+		done <- result{data, err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.data, res.err
+	case <-time.After(2 * time.Second):
+		t.Fatal("stream did not end")
+		return nil, nil
+	}
+}
+
+func TestStreamDeliversFramesInOrder(t *testing.T) {
+	client, server := CreateBakaRpc(nil, nil), CreateBakaRpc(nil, nil)
+	connect(client, server)
+
+	// More frames than the window, so the writer has to wait for acks
+	payload := bytes.Repeat([]byte("0123456789abcdef"), StreamChunkSize*(StreamWindow+4)/16+7)
+	server.RegisterStreamMethod("export", []parameters.Param{}, func(ctx context.Context, params map[string]parameters.Param, stream *StreamWriter) error {
+		_, err := io.Copy(stream, bytes.NewReader(payload))
+		return err
+	})
+
+	reader, resErr := client.CallStream(context.Background(), nil, "export", parameters.NewParametersByName(nil))
+	if resErr != nil {
+		t.Fatal(resErr)
+	}
+
+	data, err := readAll(t, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, payload) {
+		t.Fatalf("read %d bytes, want the %d bytes written in order", len(data), len(payload))
+	}
+}
+
+func TestStreamEndsWithHandlerError(t *testing.T) {
+	client, server := CreateBakaRpc(nil, nil), CreateBakaRpc(nil, nil)
+	connect(client, server)
+
+	server.RegisterStreamMethod("export", []parameters.Param{}, func(ctx context.Context, params map[string]parameters.Param, stream *StreamWriter) error {
+		_, _ = stream.Write([]byte("partial"))
+		return fmt.Errorf("disk on fire")
+	})
+
+	reader, resErr := client.CallStream(context.Background(), nil, "export", parameters.NewParametersByName(nil))
+	if resErr != nil {
+		t.Fatal(resErr)
+	}
+
+	data, err := readAll(t, reader)
+	if string(data) != "partial" {
+		t.Errorf("read %q, want partial", data)
+	}
+	if rpcErr, ok := err.(*errors.RPCError); !ok || rpcErr.Message != "disk on fire" {
+		t.Errorf("err = %v, want the handler's error", err)
+	}
+}
+
+func TestStreamCloseCancelsWriter(t *testing.T) {
+	client, server := CreateBakaRpc(nil, nil), CreateBakaRpc(nil, nil)
+	connect(client, server)
+
+	stopped := make(chan struct{})
+	server.RegisterStreamMethod("forever", []parameters.Param{}, func(ctx context.Context, params map[string]parameters.Param, stream *StreamWriter) error {
+		defer close(stopped)
+		for {
+			if _, err := stream.Write([]byte("tick")); err != nil {
+				return err
+			}
+		}
+	})
+
+	reader, resErr := client.CallStream(context.Background(), nil, "forever", parameters.NewParametersByName(nil))
+	if resErr != nil {
+		t.Fatal(resErr)
+	}
+
+	buffer := make([]byte, 4)
+	if _, err := io.ReadFull(reader, buffer); err != nil {
+		t.Fatal(err)
+	}
+	_ = reader.Close()
+
+	waitFor(t, stopped)
+}
+
+func TestStreamEndsOnDisconnect(t *testing.T) {
+	client, server := CreateBakaRpc(nil, nil), CreateBakaRpc(nil, nil)
+	channel, _ := connect(client, server)
+
+	server.RegisterStreamMethod("forever", []parameters.Param{}, func(ctx context.Context, params map[string]parameters.Param, stream *StreamWriter) error {
+		<-stream.Done()
+		return nil
+	})
+
+	reader, resErr := client.CallStream(context.Background(), channel, "forever", parameters.NewParametersByName(nil))
+	if resErr != nil {
+		t.Fatal(resErr)
+	}
+
+	client.RemoveChannels(channel)
+	_, err := readAll(t, reader)
+	if rpcErr, ok := err.(*errors.RPCError); !ok || rpcErr.Message != "Channel Closed" {
+		t.Errorf("err = %v, want Channel Closed", err)
+	}
+}
+
+func TestStreamWindowExceeded(t *testing.T) {
+	clientIn := make(chan []byte)
+	clientOut := make(chan []byte)
+	client := CreateBakaRpc(clientIn, clientOut)
+
+	// A peer ignoring flow control, sending a frame more than the window without waiting for acks
+	cancelled := make(chan string, 1)
+	go func() {
+		var call struct {
+			Id string `json:"id"`
+		}
+		_ = json.Unmarshal(<-clientOut, &call)
+		clientIn <- []byte(fmt.Sprintf(`{"jsonrpc": "2.0", "id": %q, "result": {"stream": %q}}`, call.Id, call.Id))
+
+		for seq := 0; seq <= StreamWindow; seq++ {
+			clientIn <- []byte(fmt.Sprintf(`{"jsonrpc": "2.0", "method": %q, "params": {"stream": %q, "seq": %d, "data": "AA=="}}`, StreamChunkMethod, call.Id, seq))
+		}
+
+		// Keeps draining, so the acks sent while the test reads do not block
+		for message := range clientOut {
+			if strings.Contains(string(message), StreamCancelMethod) {
+				cancelled <- string(message)
+			}
+		}
+	}()
+
+	reader, resErr := client.CallStream(context.Background(), nil, "flood", parameters.NewParametersByName(nil))
+	if resErr != nil {
+		t.Fatal(resErr)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("no $/stream.cancel sent to the peer")
+	}
+
+	data, err := readAll(t, reader)
+	if len(data) != StreamWindow {
+		t.Errorf("read %d bytes, want the %d frames within the window", len(data), StreamWindow)
+	}
+	if rpcErr, ok := err.(*errors.RPCError); !ok || rpcErr.Message != "Stream window exceeded" {
+		t.Errorf("err = %v, want Stream window exceeded", err)
+	}
+}
+
+func TestStreamThroughRetryingInterceptor(t *testing.T) {
+	client, server := CreateBakaRpc(nil, nil), CreateBakaRpc(nil, nil)
+	connect(client, server)
+	client.UseCallInterceptors(retryOnce)
+
+	var attempts int32
+	firstStopped := make(chan struct{})
+	server.RegisterStreamMethod("attempt", []parameters.Param{}, func(ctx context.Context, params map[string]parameters.Param, stream *StreamWriter) error {
+		attempt := atomic.AddInt32(&attempts, 1)
+		if attempt == 1 {
+			// Cancelled by the client once the call is retried
+			_, _ = stream.Write([]byte("first"))
+			<-stream.Done()
+			close(firstStopped)
+			return nil
+		}
+
+		_, err := stream.Write([]byte("second"))
+		return err
+	})
+
+	reader, resErr := client.CallStream(context.Background(), nil, "attempt", parameters.NewParametersByName(nil))
+	if resErr != nil {
+		t.Fatal(resErr)
+	}
+	waitFor(t, firstStopped)
+
+	data, err := readAll(t, reader)
+	if err != nil || string(data) != "second" {
+		t.Fatalf("read %q, %v, want only the second attempt", data, err)
+	}
+
+	client.streamsMutex.Lock()
+	defer client.streamsMutex.Unlock()
+	if len(client.streamReaders) != 0 {
+		t.Errorf("%d stream readers left after the stream ended", len(client.streamReaders))
+	}
+}
@@ -0,0 +1,351 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	errs "errors"
+	"sync"
+
+	UUID "github.com/nu7hatch/gouuid"
+
+	"github.com/bob620/baka-rpc-go/errors"
+	"github.com/bob620/baka-rpc-go/openrpc"
+	"github.com/bob620/baka-rpc-go/parameters"
+	"github.com/bob620/baka-rpc-go/request"
+)
+
+const (
+	// SubscriptionMethod is the notification carrying an event, or the end, of a subscription
+	SubscriptionMethod = "$/subscription"
+	// UnsubscribeMethod is the notification a subscriber sends to stop a subscription
+	UnsubscribeMethod = "$/unsubscribe"
+)
+
+// SubscriptionFunc produces events into sink until ctx is cancelled, returning ends the subscription
+type SubscriptionFunc func(ctx context.Context, params map[string]parameters.Param, sink *Sink) error
+
+// Sink pushes events of a single subscription to the subscriber
+type Sink struct {
+	rpc  *BakaRpc
+	ctx  context.Context
+	uuid *UUID.UUID
+	id   string
+}
+
+// Subscription receives the events of a subscription made with Subscribe
+type Subscription struct {
+	rpc  *BakaRpc
+	uuid *UUID.UUID
+	id   string
+
+	events   chan json.RawMessage
+	pending  []json.RawMessage
+	ended    bool
+	err      *errors.RPCError
+	wake     chan struct{}
+	stop     chan struct{}
+	stopOnce sync.Once
+	mutex    sync.Mutex
+}
+
+type subscriptionKey struct{}
+
+type subscriptionResult struct {
+	Subscription string `json:"subscription"`
+}
+
+type subscriptionEnd struct {
+	Done  bool             `json:"done"`
+	Error *errors.RPCError `json:"error,omitempty"`
+}
+
+// RegisterSubscription registers a method that starts fn in its own goroutine and answers with the subscription id
+func (rpc *BakaRpc) RegisterSubscription(name string, methodParams []parameters.Param, fn SubscriptionFunc, options ...MethodOption) {
+	rpc.RegisterContextMethod(name, methodParams, func(ctx context.Context, params map[string]parameters.Param) (json.RawMessage, error) {
+		uuid := ChannelFromContext(ctx)
+		id := requestIdFromContext(ctx)
+		if uuid == nil || id == "" {
+			return nil, errs.New("subscriptions require a request")
+		}
+
+		// The producer outlives the request, so it only ends on unsubscribe, disconnect or returning
+		subCtx, cancel := context.WithCancel(context.Background())
+		subCtx = context.WithValue(subCtx, channelKey{}, uuid)
+		key := runningKey{uuid, id}
+
+		rpc.subscriptionsMutex.Lock()
+		rpc.subscriptions[key] = cancel
+		rpc.subscriptionsMutex.Unlock()
+
+		go func() {
+			err := fn(subCtx, params, &Sink{rpc, subCtx, uuid, id})
+
+			rpc.subscriptionsMutex.Lock()
+			delete(rpc.subscriptions, key)
+			rpc.subscriptionsMutex.Unlock()
+
+			// Nothing to tell the subscriber once it unsubscribed or disconnected
+			if subCtx.Err() == nil {
+				end := subscriptionEnd{Done: true}
+				if err != nil {
+					end.Error = errors.NewGenericError(err.Error())
+				}
+				_ = rpc.sendNotification(uuid, SubscriptionMethod, map[string]interface{}{"subscription": id, "end": end})
+			}
+			cancel()
+		}()
+
+		return json.Marshal(subscriptionResult{id})
+	}, append([]MethodOption{methodKind(openrpc.KindSubscription)}, options...)...)
+}
+
+// Send pushes an event to the subscriber, it errors once the subscription has ended
+func (sink *Sink) Send(event interface{}) error {
+	if sink.ctx.Err() != nil {
+		return sink.ctx.Err()
+	}
+
+	return sink.rpc.sendNotification(sink.uuid, SubscriptionMethod, map[string]interface{}{"subscription": sink.id, "event": event})
+}
+
+// Done is closed when the subscriber unsubscribes or disconnects
+func (sink *Sink) Done() <-chan struct{} {
+	return sink.ctx.Done()
+}
+
+// Subscribe calls a subscription method, events are delivered in order on Events until the subscription ends
+func (rpc *BakaRpc) Subscribe(ctx context.Context, channelUuid *UUID.UUID, name string, params *parameters.Parameters) (*Subscription, *errors.RPCError) {
+	if channelUuid == nil {
+		channelUuid = rpc.defaultChannel()
+	}
+	if !rpc.peerSupports(channelUuid, ExtensionSubscriptions) {
+		return nil, errors.NewGenericError("Subscriptions not supported")
+	}
+
+	sub := &Subscription{
+		rpc:    rpc,
+		events: make(chan json.RawMessage),
+		wake:   make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+	}
+
+	_, resErr := rpc.CallMethodContext(context.WithValue(ctx, subscriptionKey{}, sub), channelUuid, name, params)
+	if resErr != nil {
+		sub.end(resErr)
+		return nil, resErr
+	}
+
+	// Started once the call succeeded, interceptors retrying the call must not close Events early
+	go sub.deliver()
+
+	return sub, nil
+}
+
+// Events is closed once the subscription ends, Err then reports why
+func (sub *Subscription) Events() <-chan json.RawMessage {
+	return sub.events
+}
+
+// Err returns the error the subscription ended with, nil while running or if it ended normally
+func (sub *Subscription) Err() *errors.RPCError {
+	sub.mutex.Lock()
+	defer sub.mutex.Unlock()
+
+	return sub.err
+}
+
+// Unsubscribe stops the producer on the remote and closes Events, dropping undelivered events
+func (sub *Subscription) Unsubscribe() {
+	sub.mutex.Lock()
+	ended := sub.ended
+	sub.pending = nil
+	sub.mutex.Unlock()
+
+	sub.stopOnce.Do(func() {
+		close(sub.stop)
+	})
+
+	if !ended {
+		sub.rpc.notifyMethod(context.Background(), sub.uuid, UnsubscribeMethod, parameters.NewParametersByName([]parameters.Param{
+			&parameters.StringParam{Name: "subscription", Default: sub.id},
+		}))
+	}
+	sub.end(nil)
+}
+
+// push queues an event of the attempt made on uuid with request id, events of a replaced attempt are dropped
+func (sub *Subscription) push(uuid *UUID.UUID, id string, event json.RawMessage) {
+	sub.mutex.Lock()
+	defer sub.mutex.Unlock()
+
+	if !sub.ended && sub.uuid == uuid && sub.id == id {
+		sub.pending = append(sub.pending, event)
+		sub.signal()
+	}
+}
+
+// endAttempt ends the subscription if the attempt made on uuid with request id was not replaced
+func (sub *Subscription) endAttempt(uuid *UUID.UUID, id string, err *errors.RPCError) {
+	sub.mutex.Lock()
+	defer sub.mutex.Unlock()
+
+	if sub.uuid == uuid && sub.id == id {
+		sub.endLocked(err)
+	}
+}
+
+func (sub *Subscription) end(err *errors.RPCError) {
+	sub.mutex.Lock()
+	defer sub.mutex.Unlock()
+
+	sub.endLocked(err)
+}
+
+func (sub *Subscription) endLocked(err *errors.RPCError) {
+	if sub.ended {
+		return
+	}
+	sub.ended = true
+	sub.err = err
+	sub.signal()
+
+	sub.rpc.subscribersMutex.Lock()
+	delete(sub.rpc.subscribers, sub.id)
+	sub.rpc.subscribersMutex.Unlock()
+}
+
+func (sub *Subscription) signal() {
+	select {
+	case sub.wake <- struct{}{}:
+	default:
+	}
+}
+
+// deliver moves pending events onto Events so a slow consumer never blocks the channel reader
+func (sub *Subscription) deliver() {
+	defer close(sub.events)
+
+	for range sub.wake {
+		for {
+			sub.mutex.Lock()
+			if len(sub.pending) == 0 {
+				ended := sub.ended
+				sub.mutex.Unlock()
+				if ended {
+					return
+				}
+				break
+			}
+			event := sub.pending[0]
+			sub.pending = sub.pending[1:]
+			sub.mutex.Unlock()
+
+			select {
+			case sub.events <- event:
+			case <-sub.stop:
+				return
+			}
+		}
+	}
+}
+
+// trackSubscription registers the subscriber under the request id before the call is sent, so no early event is lost.
+// An interceptor retrying the call tracks it again, replacing the previous attempt.
+func (rpc *BakaRpc) trackSubscription(ctx context.Context, uuid *UUID.UUID, req *request.Request) {
+	sub, ok := ctx.Value(subscriptionKey{}).(*Subscription)
+	if !ok {
+		return
+	}
+
+	sub.mutex.Lock()
+	previousUuid, previousId := sub.uuid, sub.id
+	sub.uuid = uuid
+	sub.id = req.GetId()
+	sub.pending = nil
+	sub.ended = false
+	sub.err = nil
+	sub.mutex.Unlock()
+
+	rpc.subscribersMutex.Lock()
+	if previousId != "" && rpc.subscribers[previousId] == sub {
+		delete(rpc.subscribers, previousId)
+	}
+	rpc.subscribers[req.GetId()] = sub
+	rpc.subscribersMutex.Unlock()
+
+	// The previous attempt may have started a producer on the remote
+	if previousId != "" {
+		rpc.notifyMethod(context.Background(), previousUuid, UnsubscribeMethod, parameters.NewParametersByName([]parameters.Param{
+			&parameters.StringParam{Name: "subscription", Default: previousId},
+		}))
+	}
+}
+
+func (sub *Subscription) isOn(uuid *UUID.UUID) bool {
+	sub.mutex.Lock()
+	defer sub.mutex.Unlock()
+
+	return sub.uuid == uuid
+}
+
+func (rpc *BakaRpc) handleSubscriptionMessage(uuid *UUID.UUID, req *request.Request) {
+	var id string
+	if !readParam(req, "subscription", &id) {
+		return
+	}
+
+	rpc.subscribersMutex.RLock()
+	sub := rpc.subscribers[id]
+	rpc.subscribersMutex.RUnlock()
+
+	if sub == nil {
+		return
+	}
+
+	var event json.RawMessage
+	end := subscriptionEnd{}
+	if readParam(req, "event", &event) {
+		sub.push(uuid, id, event)
+	} else if readParam(req, "end", &end) {
+		sub.endAttempt(uuid, id, end.Error)
+	}
+}
+
+func (rpc *BakaRpc) handleUnsubscribe(uuid *UUID.UUID, req *request.Request) {
+	var id string
+	if !readParam(req, "subscription", &id) {
+		return
+	}
+
+	rpc.subscriptionsMutex.Lock()
+	cancel := rpc.subscriptions[runningKey{uuid, id}]
+	rpc.subscriptionsMutex.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+}
+
+func (rpc *BakaRpc) endChannelSubscriptions(uuid *UUID.UUID) {
+	rpc.subscriptionsMutex.Lock()
+	for key, cancel := range rpc.subscriptions {
+		if key.uuid == uuid {
+			cancel()
+		}
+	}
+	rpc.subscriptionsMutex.Unlock()
+
+	// Collected first, ending a subscription locks it before subscribersMutex
+	var subs []*Subscription
+	rpc.subscribersMutex.RLock()
+	for _, sub := range rpc.subscribers {
+		subs = append(subs, sub)
+	}
+	rpc.subscribersMutex.RUnlock()
+
+	for _, sub := range subs {
+		if sub.isOn(uuid) {
+			sub.end(errors.NewGenericError("Channel Closed"))
+		}
+	}
+}
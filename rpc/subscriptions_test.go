@@ -0,0 +1,193 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	UUID "github.com/nu7hatch/gouuid"
+
+	"github.com/bob620/baka-rpc-go/errors"
+	"github.com/bob620/baka-rpc-go/parameters"
+)
+
+// connect links client and server with an in-memory channel pair
+func connect(client *BakaRpc, server *BakaRpc) (clientChannel *UUID.UUID, serverChannel *UUID.UUID) {
+	clientToServer := make(chan []byte)
+	serverToClient := make(chan []byte)
+
+	serverChannel = server.AddChannels(clientToServer, serverToClient)
+	clientChannel = client.AddChannels(serverToClient, clientToServer)
+	return
+}
+
+// retryOnce calls every outgoing call twice, answering with the second attempt
+func retryOnce(ctx context.Context, channelUuid *UUID.UUID, methodName string, params *parameters.Parameters, invoker CallInvoker) (*json.RawMessage, *errors.RPCError) {
+	_, _ = invoker(ctx, channelUuid, methodName, params)
+	return invoker(ctx, channelUuid, methodName, params)
+}
+
+func collectEvents(t *testing.T, sub *Subscription) []string {
+	t.Helper()
+
+	var events []string
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				return events
+			}
+			events = append(events, string(event))
+		case <-timeout:
+			t.Fatalf("subscription did not end, got %v", events)
+		}
+	}
+}
+
+func TestSubscriptionDeliversEventsInOrder(t *testing.T) {
+	client, server := CreateBakaRpc(nil, nil), CreateBakaRpc(nil, nil)
+	connect(client, server)
+
+	server.RegisterSubscription("count", []parameters.Param{}, func(ctx context.Context, params map[string]parameters.Param, sink *Sink) error {
+		for i := 0; i < 5; i++ {
+			if err := sink.Send(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	sub, resErr := client.Subscribe(context.Background(), nil, "count", parameters.NewParametersByName(nil))
+	if resErr != nil {
+		t.Fatal(resErr)
+	}
+
+	events := collectEvents(t, sub)
+	if len(events) != 5 {
+		t.Fatalf("events = %v, want 5", events)
+	}
+	for i, event := range events {
+		if event != string(rune('0'+i)) {
+			t.Fatalf("events = %v, want 0 to 4 in order", events)
+		}
+	}
+	if sub.Err() != nil {
+		t.Errorf("Err() = %v, want nil", sub.Err())
+	}
+}
+
+func TestSubscriptionUnsubscribeStopsProducer(t *testing.T) {
+	client, server := CreateBakaRpc(nil, nil), CreateBakaRpc(nil, nil)
+	connect(client, server)
+
+	stopped := make(chan struct{})
+	server.RegisterSubscription("ticks", []parameters.Param{}, func(ctx context.Context, params map[string]parameters.Param, sink *Sink) error {
+		defer close(stopped)
+		<-sink.Done()
+		return nil
+	})
+
+	sub, resErr := client.Subscribe(context.Background(), nil, "ticks", parameters.NewParametersByName(nil))
+	if resErr != nil {
+		t.Fatal(resErr)
+	}
+
+	sub.Unsubscribe()
+	waitFor(t, stopped)
+	collectEvents(t, sub)
+}
+
+func TestSubscriptionThroughRetryingInterceptor(t *testing.T) {
+	client, server := CreateBakaRpc(nil, nil), CreateBakaRpc(nil, nil)
+	connect(client, server)
+	client.UseCallInterceptors(retryOnce)
+
+	var attempts int32
+	firstStopped := make(chan struct{})
+	server.RegisterSubscription("attempt", []parameters.Param{}, func(ctx context.Context, params map[string]parameters.Param, sink *Sink) error {
+		attempt := atomic.AddInt32(&attempts, 1)
+		if attempt == 1 {
+			// The first attempt ends right away, its end must not close the retried subscription
+			_ = sink.Send(attempt)
+			close(firstStopped)
+			return nil
+		}
+
+		for i := 0; i < 3; i++ {
+			if err := sink.Send(attempt); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	sub, resErr := client.Subscribe(context.Background(), nil, "attempt", parameters.NewParametersByName(nil))
+	if resErr != nil {
+		t.Fatal(resErr)
+	}
+	waitFor(t, firstStopped)
+
+	events := collectEvents(t, sub)
+	if len(events) != 3 {
+		t.Fatalf("events = %v, want 3 events of the second attempt", events)
+	}
+	for _, event := range events {
+		if event != "2" {
+			t.Fatalf("events = %v, want only events of the second attempt", events)
+		}
+	}
+
+	client.subscribersMutex.RLock()
+	defer client.subscribersMutex.RUnlock()
+	if len(client.subscribers) != 0 {
+		t.Errorf("%d subscribers left after the subscription ended", len(client.subscribers))
+	}
+}
+
+func TestSubscriptionRetryCancelsPreviousProducer(t *testing.T) {
+	client, server := CreateBakaRpc(nil, nil), CreateBakaRpc(nil, nil)
+	connect(client, server)
+	client.UseCallInterceptors(retryOnce)
+
+	var attempts int32
+	firstStopped := make(chan struct{})
+	server.RegisterSubscription("attempt", []parameters.Param{}, func(ctx context.Context, params map[string]parameters.Param, sink *Sink) error {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			<-sink.Done()
+			close(firstStopped)
+		}
+		return nil
+	})
+
+	sub, resErr := client.Subscribe(context.Background(), nil, "attempt", parameters.NewParametersByName(nil))
+	if resErr != nil {
+		t.Fatal(resErr)
+	}
+
+	waitFor(t, firstStopped)
+	collectEvents(t, sub)
+}
+
+func TestSubscriptionEndsOnDisconnect(t *testing.T) {
+	client, server := CreateBakaRpc(nil, nil), CreateBakaRpc(nil, nil)
+	channel, _ := connect(client, server)
+
+	server.RegisterSubscription("ticks", []parameters.Param{}, func(ctx context.Context, params map[string]parameters.Param, sink *Sink) error {
+		<-sink.Done()
+		return nil
+	})
+
+	sub, resErr := client.Subscribe(context.Background(), channel, "ticks", parameters.NewParametersByName(nil))
+	if resErr != nil {
+		t.Fatal(resErr)
+	}
+
+	client.RemoveChannels(channel)
+	collectEvents(t, sub)
+	if sub.Err() == nil || sub.Err().Message != "Channel Closed" {
+		t.Errorf("Err() = %v, want Channel Closed", sub.Err())
+	}
+}
@@ -0,0 +1,184 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	UUID "github.com/nu7hatch/gouuid"
+
+	"github.com/bob620/baka-rpc-go/errors"
+	"github.com/bob620/baka-rpc-go/parameters"
+)
+
+// Reserved system methods registered by EnableSystemMethods
+const (
+	PingMethod         = "rpc.ping"
+	HealthMethod       = "rpc.health"
+	VersionMethod      = "rpc.version"
+	CapabilitiesMethod = "rpc.capabilities"
+)
+
+// Extensions to JSON-RPC 2.0 listed by rpc.capabilities
+const (
+	ExtensionCancel        = "cancel"
+	ExtensionProgress      = "progress"
+	ExtensionSubscriptions = "subscriptions"
+	ExtensionStreaming     = "streaming"
+)
+
+const modulePath = "github.com/bob620/baka-rpc-go"
+
+// AppInfo is the application reported by rpc.version
+type AppInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// HealthCheck returns an error while whatever it checks is unhealthy
+type HealthCheck func(ctx context.Context) error
+
+type healthResult struct {
+	Healthy bool                   `json:"healthy"`
+	Checks  map[string]healthCheck `json:"checks"`
+}
+
+type healthCheck struct {
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+type versionResult struct {
+	App      AppInfo `json:"app"`
+	Library  string  `json:"library"`
+	Protocol string  `json:"protocol"`
+}
+
+type capabilitiesResult struct {
+	Extensions []string `json:"extensions"`
+	Methods    []string `json:"methods"`
+}
+
+// EnableSystemMethods registers rpc.ping, rpc.health, rpc.version and rpc.capabilities. rpc.ping answers channels that
+// have not authenticated too, so it can be used for liveness.
+func (rpc *BakaRpc) EnableSystemMethods(app AppInfo) {
+	rpc.RegisterContextMethod(PingMethod, []parameters.Param{}, func(ctx context.Context, params map[string]parameters.Param) (json.RawMessage, error) {
+		return json.Marshal("pong")
+	}, methodPublic())
+
+	rpc.RegisterContextMethod(HealthMethod, []parameters.Param{}, func(ctx context.Context, params map[string]parameters.Param) (json.RawMessage, error) {
+		return json.Marshal(rpc.checkHealth(ctx))
+	})
+
+	rpc.RegisterContextMethod(VersionMethod, []parameters.Param{}, func(ctx context.Context, params map[string]parameters.Param) (json.RawMessage, error) {
+		return json.Marshal(versionResult{App: app, Library: LibraryVersion(), Protocol: "2.0"})
+	})
+
+	rpc.RegisterContextMethod(CapabilitiesMethod, []parameters.Param{}, func(ctx context.Context, params map[string]parameters.Param) (json.RawMessage, error) {
+		return json.Marshal(capabilitiesResult{Extensions: rpc.Extensions(), Methods: rpc.NamespaceMethods("rpc.")})
+	})
+}
+
+// AddHealthCheck adds a check to rpc.health under name, replacing any check already named so
+func (rpc *BakaRpc) AddHealthCheck(name string, check HealthCheck) {
+	rpc.healthMutex.Lock()
+	defer rpc.healthMutex.Unlock()
+
+	rpc.healthChecks[name] = check
+}
+
+func (rpc *BakaRpc) RemoveHealthCheck(name string) {
+	rpc.healthMutex.Lock()
+	defer rpc.healthMutex.Unlock()
+
+	delete(rpc.healthChecks, name)
+}
+
+// checkHealth runs every health check concurrently, healthy only when all of them are
+func (rpc *BakaRpc) checkHealth(ctx context.Context) healthResult {
+	rpc.healthMutex.RLock()
+	checks := make(map[string]HealthCheck, len(rpc.healthChecks))
+	for name, check := range rpc.healthChecks {
+		checks[name] = check
+	}
+	rpc.healthMutex.RUnlock()
+
+	result := healthResult{Healthy: true, Checks: map[string]healthCheck{}}
+	resultMutex := sync.Mutex{}
+	wait := sync.WaitGroup{}
+	for name, check := range checks {
+		wait.Add(1)
+		go func(name string, check HealthCheck) {
+			defer wait.Done()
+			err := check(ctx)
+
+			resultMutex.Lock()
+			defer resultMutex.Unlock()
+			if err != nil {
+				result.Healthy = false
+				result.Checks[name] = healthCheck{Healthy: false, Error: err.Error()}
+			} else {
+				result.Checks[name] = healthCheck{Healthy: true}
+			}
+		}(name, check)
+	}
+	wait.Wait()
+
+	return result
+}
+
+// Extensions returns the JSON-RPC 2.0 extensions this side supports, the built in ones followed by those offered with
+// EnableNegotiation
+func (rpc *BakaRpc) Extensions() []string {
+	extensions := []string{ExtensionCancel, ExtensionProgress, ExtensionSubscriptions, ExtensionStreaming}
+
+	rpc.negotiationMutex.RLock()
+	defer rpc.negotiationMutex.RUnlock()
+
+	for _, extension := range rpc.negotiation.extensions {
+		if !containsString(extensions, extension) {
+			extensions = append(extensions, extension)
+		}
+	}
+
+	return extensions
+}
+
+// LibraryVersion returns the version of Baka-RPC the binary was built with, (devel) when it is unknown
+func LibraryVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "(devel)"
+	}
+
+	version := ""
+	if info.Main.Path == modulePath {
+		version = info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath || strings.HasPrefix(dep.Path, modulePath+"/") {
+			version = dep.Version
+			if dep.Replace != nil {
+				version = dep.Replace.Version
+			}
+		}
+	}
+
+	if version == "" {
+		return "(devel)"
+	}
+	return version
+}
+
+// Ping calls rpc.ping on the channel, returning the round trip time
+func (rpc *BakaRpc) Ping(ctx context.Context, uuid *UUID.UUID) (time.Duration, *errors.RPCError) {
+	start := time.Now()
+	if _, resErr := rpc.CallMethodContext(ctx, uuid, PingMethod, parameters.NewParametersByName(nil)); resErr != nil {
+		return 0, resErr
+	}
+
+	return time.Since(start), nil
+}
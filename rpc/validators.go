@@ -0,0 +1,124 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	errs "errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/bob620/baka-rpc-go/errors"
+	"github.com/bob620/baka-rpc-go/parameters"
+)
+
+// ParamValidator checks a param the caller set before the handler is called, it may normalise the value by setting the
+// param's data. Returning a *parameters.ValidationError reports its violations below the param's path.
+type ParamValidator func(ctx context.Context, param parameters.Param) error
+
+// ParamsValidator checks the params together, for rules across params such as an end after a start. Returning a
+// *parameters.ValidationError reports its violations as they are.
+type ParamsValidator func(ctx context.Context, params map[string]parameters.Param) error
+
+// MethodParamValidators runs validators in order on the named param when the caller set it
+func MethodParamValidators(name string, validators ...ParamValidator) MethodOption {
+	return func(method *method) {
+		if method.paramValidators == nil {
+			method.paramValidators = map[string][]ParamValidator{}
+		}
+		method.paramValidators[name] = append(method.paramValidators[name], validators...)
+	}
+}
+
+// MethodValidators runs validators in order once every param passed its own validators
+func MethodValidators(validators ...ParamsValidator) MethodOption {
+	return func(method *method) {
+		method.validators = append(method.validators, validators...)
+	}
+}
+
+// validate runs the method's validators on the bound params, answering with every violation found. Validators returning
+// an *errors.RPCError answer with it instead.
+func (method *method) validate(ctx context.Context, params map[string]parameters.Param) *errors.RPCError {
+	var violations []parameters.Violation
+	for _, param := range method.params {
+		name := param.GetName()
+		if !params[name].IsSet() {
+			continue
+		}
+
+		for _, validator := range method.paramValidators[name] {
+			err := handlerError(validator(ctx, params[name]))
+			if rpcErr, ok := err.(*errors.RPCError); ok {
+				return rpcErr
+			} else if err != nil {
+				violations = append(violations, paramViolations(name, err)...)
+				break
+			}
+		}
+	}
+
+	if len(violations) == 0 {
+		for _, validator := range method.validators {
+			err := handlerError(validator(ctx, params))
+			var validationErr *parameters.ValidationError
+			if rpcErr, ok := err.(*errors.RPCError); ok {
+				return rpcErr
+			} else if errs.As(err, &validationErr) {
+				violations = append(violations, validationErr.Violations...)
+			} else if err != nil {
+				violations = append(violations, parameters.Violation{Path: "", Message: err.Error()})
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		return errors.NewInvalidParams().WithData(parameters.ValidationError{Violations: violations})
+	}
+
+	return nil
+}
+
+// TrimSpace removes the leading and trailing white space of a string param
+func TrimSpace() ParamValidator {
+	return transformString(strings.TrimSpace)
+}
+
+// ToLower lowercases a string param, such as an email address
+func ToLower() ParamValidator {
+	return transformString(strings.ToLower)
+}
+
+// MatchPattern rejects string params not matching pattern
+func MatchPattern(pattern *regexp.Regexp) ParamValidator {
+	return func(ctx context.Context, param parameters.Param) error {
+		var value string
+		if err := json.Unmarshal(param.GetData(), &value); err != nil {
+			return err
+		}
+
+		if !pattern.MatchString(value) {
+			return fmt.Errorf("must match pattern %s", pattern.String())
+		}
+		return nil
+	}
+}
+
+func transformString(transform func(string) string) ParamValidator {
+	return func(ctx context.Context, param parameters.Param) error {
+		if param.IsNull() {
+			return nil
+		}
+
+		var value string
+		if err := json.Unmarshal(param.GetData(), &value); err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(transform(value))
+		if err != nil {
+			return err
+		}
+		return param.SetData(data)
+	}
+}
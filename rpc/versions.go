@@ -0,0 +1,190 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"unicode"
+
+	UUID "github.com/nu7hatch/gouuid"
+
+	"github.com/bob620/baka-rpc-go/errors"
+	"github.com/bob620/baka-rpc-go/parameters"
+)
+
+// VersionSeparator separates a method's name from its version when registering versions, as in getItem@v2
+const VersionSeparator = "@"
+
+// SelectVersionMethod is the reserved method a channel calls to choose the version its unversioned calls are routed to
+const SelectVersionMethod = "rpc.selectVersion"
+
+// Deprecation is a call to a method registered with MethodDeprecated
+type Deprecation struct {
+	Channel *UUID.UUID
+	// Method is the name called, Resolved the versioned method it was routed to
+	Method   string
+	Resolved string
+	Message  string
+}
+
+// MethodDeprecated reports every call of the method to the deprecation handle, message tells callers what to use instead
+func MethodDeprecated(message string) MethodOption {
+	return func(method *method) {
+		method.deprecated = message
+		if method.deprecated == "" {
+			method.deprecated = "deprecated"
+		}
+	}
+}
+
+// HandleDeprecation is called with every call of a deprecated method before it is dispatched
+func (rpc *BakaRpc) HandleDeprecation(handle func(ctx context.Context, deprecation Deprecation)) {
+	rpc.methodsMutex.Lock()
+	defer rpc.methodsMutex.Unlock()
+
+	rpc.deprecationHandle = handle
+}
+
+// ChannelVersion routes the channel's unversioned calls to version
+func ChannelVersion(version string) ChannelOption {
+	return func(config *channelConfig) {
+		config.version = version
+	}
+}
+
+func (rpc *BakaRpc) SetChannelVersion(uuid *UUID.UUID, version string) {
+	rpc.chansMutex.Lock()
+	defer rpc.chansMutex.Unlock()
+
+	if rpc.chansOut[uuid] != nil {
+		rpc.chansVersion[uuid] = version
+	}
+}
+
+func (rpc *BakaRpc) GetChannelVersion(uuid *UUID.UUID) string {
+	rpc.chansMutex.RLock()
+	defer rpc.chansMutex.RUnlock()
+
+	return rpc.chansVersion[uuid]
+}
+
+// EnableVersionSelection registers rpc.selectVersion, letting channels choose their version with a "version" param.
+// Versions no method was registered with are rejected with Invalid params.
+func (rpc *BakaRpc) EnableVersionSelection() {
+	rpc.RegisterContextMethod(SelectVersionMethod, []parameters.Param{
+		&parameters.StringParam{Name: "version", Required: true},
+	}, func(ctx context.Context, params map[string]parameters.Param) (json.RawMessage, error) {
+		version, _ := params["version"].(*parameters.StringParam).GetString()
+		if !rpc.hasVersion(version) {
+			return nil, errors.NewInvalidParams().WithData(parameters.ValidationError{Violations: []parameters.Violation{
+				{Path: "/version", Message: "is not a known version"},
+			}})
+		}
+
+		rpc.SetChannelVersion(ChannelFromContext(ctx), version)
+		return json.Marshal(version)
+	}, methodPublic())
+}
+
+func (rpc *BakaRpc) hasVersion(version string) bool {
+	rpc.methodsMutex.RLock()
+	defer rpc.methodsMutex.RUnlock()
+
+	for _, versions := range rpc.versions {
+		for _, registered := range versions {
+			if registered == version {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// resolveMethod returns the method called name, routing unversioned names of versioned methods to the highest version
+// up to the channel's version, or the highest version when the channel has none. Expects methodsMutex to be held.
+func (rpc *BakaRpc) resolveMethod(uuid *UUID.UUID, name string) *method {
+	if method := rpc.methods[name]; method != nil || strings.Contains(name, VersionSeparator) {
+		return method
+	}
+
+	versions := rpc.versions[name]
+	if len(versions) == 0 {
+		return nil
+	}
+
+	channelVersion := rpc.GetChannelVersion(uuid)
+	for index := len(versions) - 1; index >= 0; index-- {
+		if channelVersion == "" || compareVersions(versions[index], channelVersion) <= 0 {
+			return rpc.methods[name+VersionSeparator+versions[index]]
+		}
+	}
+
+	return nil
+}
+
+// indexVersion records the version of a method registered as name@version, keeping versions sorted. Expects
+// methodsMutex to be held.
+func (rpc *BakaRpc) indexVersion(methodName string, registered bool) {
+	index := strings.LastIndex(methodName, VersionSeparator)
+	if index < 0 {
+		return
+	}
+	name, version := methodName[:index], methodName[index+len(VersionSeparator):]
+
+	versions := rpc.versions[name][:0:0]
+	for _, existing := range rpc.versions[name] {
+		if existing != version {
+			versions = append(versions, existing)
+		}
+	}
+
+	if registered {
+		position := len(versions)
+		for position > 0 && compareVersions(versions[position-1], version) > 0 {
+			position--
+		}
+		versions = append(versions[:position], append([]string{version}, versions[position:]...)...)
+	}
+
+	if len(versions) == 0 {
+		delete(rpc.versions, name)
+	} else {
+		rpc.versions[name] = versions
+	}
+}
+
+// compareVersions orders versions comparing runs of digits as numbers, so v2 comes before v10
+func compareVersions(a, b string) int {
+	for a != "" && b != "" {
+		partA, restA := versionPart(a)
+		partB, restB := versionPart(b)
+
+		numberA, errA := strconv.Atoi(partA)
+		numberB, errB := strconv.Atoi(partB)
+		if errA == nil && errB == nil && numberA != numberB {
+			if numberA < numberB {
+				return -1
+			}
+			return 1
+		} else if (errA != nil || errB != nil) && partA != partB {
+			return strings.Compare(partA, partB)
+		}
+
+		a, b = restA, restB
+	}
+
+	return strings.Compare(a, b)
+}
+
+// versionPart splits off the leading run of digits or of anything else
+func versionPart(version string) (part string, rest string) {
+	digits := unicode.IsDigit(rune(version[0]))
+	end := 1
+	for end < len(version) && unicode.IsDigit(rune(version[end])) == digits {
+		end++
+	}
+
+	return version[:end], version[end:]
+}
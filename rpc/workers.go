@@ -0,0 +1,132 @@
+package rpc
+
+import (
+	"encoding/json"
+	"sync/atomic"
+
+	UUID "github.com/nu7hatch/gouuid"
+
+	"github.com/bob620/baka-rpc-go/errors"
+	"github.com/bob620/baka-rpc-go/request"
+	"github.com/bob620/baka-rpc-go/response"
+)
+
+// Limits bounds how many incoming requests are processed at once, zero values are unlimited
+type Limits struct {
+	// Workers is the size of the global worker pool, 0 spawns a goroutine for every request. A handler still running
+	// after its MethodTimeout elapsed no longer holds a worker.
+	Workers int
+	// MaxInFlightPerChannel is the most requests a single channel may have queued or running
+	MaxInFlightPerChannel int
+	// QueueDepth is how many requests may wait for a free worker before new requests are rejected
+	QueueDepth int
+}
+
+type Stats struct {
+	QueueLength int
+	InFlight    int
+	Rejected    uint64
+}
+
+// SetLimits replaces the worker pool, requests already queued on the previous pool are still processed
+func (rpc *BakaRpc) SetLimits(limits Limits) {
+	rpc.limitsMutex.Lock()
+	defer rpc.limitsMutex.Unlock()
+
+	if rpc.queue != nil {
+		close(rpc.queue)
+		rpc.queue = nil
+		rpc.slots = nil
+	}
+
+	rpc.limits = limits
+	if limits.Workers > 0 {
+		// Every accepted request holds a slot until it finishes, so the queue itself never blocks
+		rpc.slots = make(chan struct{}, limits.Workers+limits.QueueDepth)
+		queue := make(chan func(), limits.Workers+limits.QueueDepth)
+		for i := 0; i < limits.Workers; i++ {
+			go func() {
+				for job := range queue {
+					job()
+				}
+			}()
+		}
+		rpc.queue = queue
+	}
+}
+
+func (rpc *BakaRpc) Stats() Stats {
+	rpc.limitsMutex.RLock()
+	defer rpc.limitsMutex.RUnlock()
+
+	inFlight := 0
+	for _, count := range rpc.inFlight {
+		inFlight += count
+	}
+
+	return Stats{
+		QueueLength: len(rpc.queue),
+		InFlight:    inFlight,
+		Rejected:    atomic.LoadUint64(&rpc.rejected),
+	}
+}
+
+func (rpc *BakaRpc) dispatch(uuid *UUID.UUID, req *request.Request) {
+	rpc.limitsMutex.Lock()
+	if rpc.limits.MaxInFlightPerChannel > 0 && rpc.inFlight[uuid] >= rpc.limits.MaxInFlightPerChannel {
+		rpc.limitsMutex.Unlock()
+		rpc.reject(uuid, req)
+		return
+	}
+	rpc.inFlight[uuid]++
+	rpc.limitsMutex.Unlock()
+
+	// Track the request before it is queued so it can be cancelled while waiting for a worker
+	ctx, done := rpc.trackRequest(uuid, req)
+	job := func() {
+		defer rpc.release(uuid)
+		defer done()
+		rpc.processRequest(ctx, uuid, req)
+	}
+
+	rpc.limitsMutex.RLock()
+	defer rpc.limitsMutex.RUnlock()
+
+	if rpc.queue == nil {
+		go job()
+		return
+	}
+
+	slots := rpc.slots
+	select {
+	case slots <- struct{}{}:
+		rpc.queue <- func() {
+			defer func() { <-slots }()
+			job()
+		}
+	default:
+		// Release without the write lock we are already holding the read side of
+		go func() {
+			done()
+			rpc.release(uuid)
+			rpc.reject(uuid, req)
+		}()
+	}
+}
+
+func (rpc *BakaRpc) release(uuid *UUID.UUID) {
+	rpc.limitsMutex.Lock()
+	defer rpc.limitsMutex.Unlock()
+
+	rpc.inFlight[uuid]--
+	if rpc.inFlight[uuid] <= 0 {
+		delete(rpc.inFlight, uuid)
+	}
+}
+
+func (rpc *BakaRpc) reject(uuid *UUID.UUID, req *request.Request) {
+	atomic.AddUint64(&rpc.rejected, 1)
+
+	data, _ := json.Marshal(response.NewErrorResponse(req.GetId(), errors.NewServerBusy()))
+	go rpc.sendMessage(data, uuid)
+}
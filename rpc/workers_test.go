@@ -0,0 +1,85 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bob620/baka-rpc-go/errors"
+	"github.com/bob620/baka-rpc-go/parameters"
+)
+
+func TestWorkerPoolQueuesThenRejects(t *testing.T) {
+	clientToServer := make(chan []byte)
+	serverToClient := make(chan []byte)
+	server := CreateBakaRpc(clientToServer, serverToClient)
+	client := CreateBakaRpc(serverToClient, clientToServer)
+
+	server.SetLimits(Limits{Workers: 1, QueueDepth: 1})
+
+	started := make(chan struct{}, 2)
+	unblock := make(chan struct{})
+	server.RegisterContextMethod("block", []parameters.Param{}, func(ctx context.Context, params map[string]parameters.Param) (json.RawMessage, error) {
+		started <- struct{}{}
+		<-unblock
+		return json.RawMessage(`true`), nil
+	})
+
+	results := make(chan *errors.RPCError, 2)
+	call := func() {
+		_, resErr := client.CallMethodWithNone(nil, "block")
+		results <- resErr
+	}
+
+	// The first call takes the only worker, the second waits in the queue
+	go call()
+	waitFor(t, started)
+	go call()
+	waitUntil(t, func() bool { return server.Stats().QueueLength == 1 })
+
+	_, resErr := client.CallMethodWithNone(nil, "block")
+	if resErr == nil || resErr.Code != errors.NewServerBusy().Code {
+		t.Fatalf("third call = %v, want Server busy", resErr)
+	}
+
+	close(unblock)
+	for i := 0; i < 2; i++ {
+		select {
+		case resErr := <-results:
+			if resErr != nil {
+				t.Fatalf("accepted call failed: %v", resErr)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("accepted call did not complete")
+		}
+	}
+
+	stats := server.Stats()
+	if stats.Rejected != 1 {
+		t.Errorf("Rejected = %d, want 1", stats.Rejected)
+	}
+	waitUntil(t, func() bool { return server.Stats().InFlight == 0 })
+}
+
+func waitFor(t *testing.T, signal <-chan struct{}) {
+	t.Helper()
+
+	select {
+	case <-signal:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the handler")
+	}
+}
+
+func waitUntil(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}